@@ -0,0 +1,57 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/output"
+)
+
+// cliOptions are the options that are shared by the commands that evaluate the replication
+// in the cluster of the current kubeconfig without running the controller, e.g. plan and inspect.
+type cliOptions struct {
+	output               string
+	alternativePrefixes  []string
+	preservedAnnotations []string
+}
+
+// AddFlags adds the shared flags. The objects describe what is printed by the command.
+func (o *cliOptions) AddFlags(fs *pflag.FlagSet, objects string) {
+	fs.StringVarP(&o.output, "output", "o", output.Table, output.Description(objects))
+	fs.StringArrayVar(&o.alternativePrefixes, "prefix", []string{},
+		fmt.Sprintf("define alternate annotation prefixes. Defaults to %q", v1alpha1.DefaultAnnotationPrefix))
+	fs.StringSliceVar(&o.preservedAnnotations, "preserve-annotations", []string{},
+		"annotations of source resources that are copied to their replicas. Entries ending with '*' match all annotations with that prefix")
+
+	fs.AddGoFlagSet(flag.CommandLine)
+}
+
+// newClient configures the annotations like the controller
+// and creates a client for the cluster of the current kubeconfig.
+func (o *cliOptions) newClient() (ctrlclient.Client, *rest.Config, error) {
+	configureAnnotations(logr.Discard(), o.alternativePrefixes, o.preservedAnnotations)
+
+	restConfig, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, nil, err
+	}
+	kubeClient, err := ctrlclient.New(restConfig, ctrlclient.Options{Scheme: scheme})
+	if err != nil {
+		return nil, nil, err
+	}
+	return kubeClient, restConfig, nil
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/schrodit/secret-replication-controller/pkg/inspect"
+)
+
+type inspectOptions struct {
+	cliOptions
+	source types.NamespacedName
+}
+
+// NewInspectCmd creates a new command that prints all replicas of a source secret and whether they are in sync.
+func NewInspectCmd(ctx context.Context) *cobra.Command {
+	opts := &inspectOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "inspect <namespace>/<name>",
+		Short: "Prints all replicas of a secret and whether they are in-sync, stale or drifted",
+		Long: "Prints all replicas of a secret and whether they are in-sync, stale or drifted.\n" +
+			"The command exits with exit code 2 if at least one replica is stale or drifted and with exit code 1 on errors.",
+		Args: cobra.ExactArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.Complete(args); err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+			outOfSync, err := opts.run(ctx)
+			if err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+			if outOfSync {
+				os.Exit(2)
+			}
+			return nil
+		},
+	}
+
+	opts.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (o *inspectOptions) AddFlags(fs *pflag.FlagSet) {
+	o.cliOptions.AddFlags(fs, "replicas")
+}
+
+// Complete parses the source given as argument.
+func (o *inspectOptions) Complete(args []string) error {
+	parts := strings.Split(args[0], string(types.Separator))
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return fmt.Errorf("invalid source %q: expected the format <namespace>/<name>", args[0])
+	}
+	o.source = types.NamespacedName{Namespace: parts[0], Name: parts[1]}
+	return nil
+}
+
+// run prints the replicas of the source and returns whether at least one replica is not in sync.
+func (o *inspectOptions) run(ctx context.Context) (bool, error) {
+	kubeClient, _, err := o.newClient()
+	if err != nil {
+		return false, err
+	}
+
+	replicas, err := inspect.Inspect(ctx, kubeClient, o.source)
+	if err != nil {
+		return false, err
+	}
+	if err := inspect.Print(os.Stdout, replicas, o.output); err != nil {
+		return false, err
+	}
+	return inspect.OutOfSync(replicas), nil
+}
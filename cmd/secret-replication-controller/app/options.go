@@ -3,22 +3,34 @@ package app
 import (
 	"flag"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
 	"github.com/schrodit/secret-replication-controller/pkg/logger"
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
 	"github.com/spf13/pflag"
 )
 
 type options struct {
-	metricsAddr              string
-	enableLeaderElection     bool
-	resyncPeriod             time.Duration
-	logConfig                *logger.Config
-	disableSecretController  bool
-	disableIngressController bool
-	alternativePrefixes      []string
+	metricsAddr                     string
+	enableLeaderElection            bool
+	resyncPeriod                    time.Duration
+	logConfig                       *logger.Config
+	disableSecretController         bool
+	disableConfigMapController      bool
+	disableIngressController        bool
+	disableGatewayController        bool
+	disablePolicyController         bool
+	disableServiceAccountController bool
+	enablePodPullSecrets            bool
+	alternativePrefixes             []string
+	preservedAnnotations            []string
+	namespace                       string
+	maxConcurrentReconciles         int
+	replicationConcurrency          int
+	enableWebhooks                  bool
 
 	log logr.Logger
 }
@@ -31,11 +43,12 @@ func (o *options) Complete() error {
 	}
 	o.log = log
 
-	for _, prefix := range o.alternativePrefixes {
-		log.Info(fmt.Sprintf("Configuring alternative 'allNamespaces' annotation %q", v1alpha1.SecretReplicationAllNamespacesAnnotations.Add(prefix)))
-		log.Info(fmt.Sprintf("Configuring alternative 'fromNamespace' annotation %q", v1alpha1.SecretReplicationFromNamespaceAnnotations.Add(prefix)))
-		log.Info(fmt.Sprintf("Configuring alternative 'namespaces' annotation %q", v1alpha1.SecretReplicationNamespacesAnnotations.Add(prefix)))
+	configureAnnotations(log, o.alternativePrefixes, o.preservedAnnotations)
+
+	if o.replicationConcurrency < 1 {
+		return fmt.Errorf("the replication concurrency has to be at least 1 but is %d", o.replicationConcurrency)
 	}
+	replicator.MaxConcurrentReplications = o.replicationConcurrency
 
 	return nil
 }
@@ -51,11 +64,57 @@ func (o *options) AddFlags(fs *pflag.FlagSet) {
 			"Enabling this will ensure there is only one active controller manager.")
 	fs.DurationVar(&o.resyncPeriod, "resync-period", 10*time.Minute, "Resync interval for the cache if the controller")
 	fs.BoolVar(&o.disableSecretController, "disable-secret", false, "Disables the secret controller")
+	fs.BoolVar(&o.disableConfigMapController, "disable-configmap", false, "Disables the configmap controller")
 	fs.BoolVar(&o.disableIngressController, "disable-ingress", false, "Disables the ingress controller")
+	fs.BoolVar(&o.disableGatewayController, "disable-gateway", false, "Disables the gateway controller")
+	fs.BoolVar(&o.disableServiceAccountController, "disable-serviceaccount", false, "Disables the service account image pull secret controller")
+	fs.BoolVar(&o.enablePodPullSecrets, "enable-pod-pull-secrets", false, "Enables the replication of image pull secrets that are referenced by pods")
+	fs.BoolVar(&o.disablePolicyController, "disable-policy", false, "Disables the replication policy controller")
 	fs.StringArrayVar(&o.alternativePrefixes, "prefix", []string{},
 		fmt.Sprintf("define alternate annotation prefixes. Defaults to %q", v1alpha1.DefaultAnnotationPrefix))
 
+	fs.StringSliceVar(&o.preservedAnnotations, "preserve-annotations", []string{},
+		"annotations of source resources that are copied to their replicas. Entries ending with '*' match all annotations with that prefix")
+
+	fs.StringVar(&o.namespace, "namespace", os.Getenv("POD_NAMESPACE"),
+		"namespace of the controller that contains the kubeconfig secrets of remote clusters. Replication to remote clusters is disabled if empty")
+
+	fs.IntVar(&o.maxConcurrentReconciles, "max-concurrent-reconciles", 1,
+		"maximum number of objects that are reconciled in parallel by each controller")
+	fs.IntVar(&o.replicationConcurrency, "replication-concurrency", 10,
+		"maximum number of replicas of one source that are written in parallel")
+
+	fs.BoolVar(&o.enableWebhooks, "enable-webhooks", false,
+		"Enables the admission webhooks that validate the replication annotations of secrets and ingresses "+
+			"and that inject replicas into secrets and service accounts on creation")
+
 	o.logConfig = logger.AddFlags(fs)
 
 	fs.AddGoFlagSet(flag.CommandLine)
 }
+
+// configureAnnotations configures the alternative annotation prefixes and the annotations that are preserved on replicas.
+func configureAnnotations(log logr.Logger, prefixes []string, preservedAnnotations []string) {
+	for _, prefix := range prefixes {
+		log.Info(fmt.Sprintf("Configuring alternative 'allNamespaces' annotation %q", v1alpha1.SecretReplicationAllNamespacesAnnotations.Add(prefix)))
+		log.Info(fmt.Sprintf("Configuring alternative 'fromNamespace' annotation %q", v1alpha1.SecretReplicationFromNamespaceAnnotations.Add(prefix)))
+		log.Info(fmt.Sprintf("Configuring alternative 'namespaces' annotation %q", v1alpha1.SecretReplicationNamespacesAnnotations.Add(prefix)))
+		log.Info(fmt.Sprintf("Configuring alternative 'namespaceSelector' annotation %q", v1alpha1.SecretReplicationNamespaceSelectorAnnotations.Add(prefix)))
+		log.Info(fmt.Sprintf("Configuring alternative 'excludeNamespaces' annotation %q", v1alpha1.SecretReplicationExcludeNamespacesAnnotations.Add(prefix)))
+		log.Info(fmt.Sprintf("Configuring alternative 'preserveAnnotations' annotation %q", v1alpha1.SecretReplicationPreserveAnnotationsAnnotations.Add(prefix)))
+		log.Info(fmt.Sprintf("Configuring alternative 'targetName' annotation %q", v1alpha1.SecretReplicationTargetNameAnnotations.Add(prefix)))
+		log.Info(fmt.Sprintf("Configuring alternative 'keyMapping' annotation %q", v1alpha1.SecretReplicationKeyMappingAnnotations.Add(prefix)))
+		log.Info(fmt.Sprintf("Configuring alternative 'includeKeys' annotation %q", v1alpha1.SecretReplicationIncludeKeysAnnotations.Add(prefix)))
+		log.Info(fmt.Sprintf("Configuring alternative 'excludeKeys' annotation %q", v1alpha1.SecretReplicationExcludeKeysAnnotations.Add(prefix)))
+		log.Info(fmt.Sprintf("Configuring alternative 'replicateFrom' annotation %q", v1alpha1.SecretReplicationReplicateFromAnnotations.Add(prefix)))
+		log.Info(fmt.Sprintf("Configuring alternative 'allowedNamespaces' annotation %q", v1alpha1.SecretReplicationAllowedNamespacesAnnotations.Add(prefix)))
+		log.Info(fmt.Sprintf("Configuring alternative 'allowedNamespaceSelector' annotation %q", v1alpha1.SecretReplicationAllowedNamespaceSelectorAnnotations.Add(prefix)))
+		log.Info(fmt.Sprintf("Configuring alternative 'clusters' annotation %q", v1alpha1.SecretReplicationClustersAnnotations.Add(prefix)))
+		log.Info(fmt.Sprintf("Configuring alternative 'conflictPolicy' annotation %q", v1alpha1.SecretReplicationConflictPolicyAnnotations.Add(prefix)))
+	}
+
+	if len(preservedAnnotations) != 0 {
+		log.Info(fmt.Sprintf("Preserving annotations %q on replicas", preservedAnnotations))
+		replicator.DefaultPreservedAnnotations.Insert(preservedAnnotations...)
+	}
+}
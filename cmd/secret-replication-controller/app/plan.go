@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	ingressctrl "github.com/schrodit/secret-replication-controller/pkg/controllers/ingress"
+	"github.com/schrodit/secret-replication-controller/pkg/plan"
+)
+
+type planOptions struct {
+	cliOptions
+	disableIngress bool
+}
+
+// NewPlanCmd creates a new command that prints the changes the controller would perform without performing them.
+func NewPlanCmd(ctx context.Context) *cobra.Command {
+	opts := &planOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Prints the replicas that would be created, updated, skipped or deleted without changing the cluster",
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.run(ctx); err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+			return nil
+		},
+	}
+
+	opts.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (o *planOptions) AddFlags(fs *pflag.FlagSet) {
+	o.cliOptions.AddFlags(fs, "changes")
+	fs.BoolVar(&o.disableIngress, "disable-ingress", false, "Does not evaluate ingresses")
+}
+
+func (o *planOptions) run(ctx context.Context) error {
+	kubeClient, restConfig, err := o.newClient()
+	if err != nil {
+		return err
+	}
+
+	var ingress ctrlclient.Object
+	if !o.disableIngress {
+		version, err := ingressctrl.DetectIngressVersion(restConfig)
+		if err != nil {
+			return err
+		}
+		ingress, err = ingressctrl.NewIngress(version)
+		if err != nil {
+			return err
+		}
+	}
+
+	changes, err := plan.New(kubeClient, ingress).Plan(ctx)
+	if err != nil {
+		return err
+	}
+	return plan.Print(os.Stdout, changes, o.output)
+}
@@ -5,10 +5,20 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/clusters"
+	configmapctrl "github.com/schrodit/secret-replication-controller/pkg/controllers/configmap"
+	gatewayctrl "github.com/schrodit/secret-replication-controller/pkg/controllers/gateway"
 	ingressctrl "github.com/schrodit/secret-replication-controller/pkg/controllers/ingress"
+	policyctrl "github.com/schrodit/secret-replication-controller/pkg/controllers/policy"
 	secretctrl "github.com/schrodit/secret-replication-controller/pkg/controllers/secret"
+	serviceaccountctrl "github.com/schrodit/secret-replication-controller/pkg/controllers/serviceaccount"
+	"github.com/schrodit/secret-replication-controller/pkg/webhooks"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 )
 
 // NewSecretReplicationControllerCmd creates a new secret replication controller coommand.
@@ -34,6 +44,8 @@ func NewSecretReplicationControllerCmd(ctx context.Context) *cobra.Command {
 	}
 
 	opts.AddFlags(cmd.Flags())
+	cmd.AddCommand(NewPlanCmd(ctx))
+	cmd.AddCommand(NewInspectCmd(ctx))
 
 	return cmd
 }
@@ -47,7 +59,16 @@ func (o *options) run(ctx context.Context) error {
 
 	ctrl.SetLogger(o.log)
 
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return err
+	}
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		return err
+	}
+
 	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
+		Scheme:             scheme,
 		MetricsBindAddress: o.metricsAddr,
 		Port:               9443,
 		LeaderElection:     o.enableLeaderElection,
@@ -59,14 +80,54 @@ func (o *options) run(ctx context.Context) error {
 		return err
 	}
 
+	controllerOpts := controller.Options{
+		MaxConcurrentReconciles: o.maxConcurrentReconciles,
+	}
+
+	var clusterCache *clusters.Cache
+	if len(o.namespace) != 0 {
+		o.log.Info(fmt.Sprintf("Reading remote cluster kubeconfigs from namespace %q", o.namespace))
+		clusterCache = clusters.NewCache(mgr.GetClient(), mgr.GetScheme(), o.namespace)
+	}
+
 	if !o.disableSecretController {
-		if err := secretctrl.AddToMgr(o.log, mgr); err != nil {
+		if err := secretctrl.AddToMgr(o.log, mgr, clusterCache, controllerOpts); err != nil {
+			return err
+		}
+	}
+
+	if !o.disableConfigMapController {
+		if err := configmapctrl.AddToMgr(o.log, mgr, clusterCache, controllerOpts); err != nil {
 			return err
 		}
 	}
 
 	if !o.disableIngressController {
-		if err := ingressctrl.AddToMgr(o.log, mgr); err != nil {
+		if err := ingressctrl.AddToMgr(o.log, mgr, controllerOpts); err != nil {
+			return err
+		}
+	}
+
+	if !o.disableGatewayController {
+		if err := gatewayctrl.AddToMgr(o.log, mgr, controllerOpts); err != nil {
+			return err
+		}
+	}
+
+	if !o.disableServiceAccountController {
+		if err := serviceaccountctrl.AddToMgr(o.log, mgr, o.enablePodPullSecrets, controllerOpts); err != nil {
+			return err
+		}
+	}
+
+	if !o.disablePolicyController {
+		if err := policyctrl.AddToMgr(o.log, mgr, controllerOpts); err != nil {
+			return err
+		}
+	}
+
+	if o.enableWebhooks {
+		if err := webhooks.AddToMgr(o.log.WithName("webhooks"), mgr); err != nil {
 			return err
 		}
 	}
@@ -19,6 +19,90 @@ const (
 	// SecretReplicationAllNamespacesAnnotation is the name of the annotation that defines that the annotated resource should be replicated to all namespaces.
 	SecretReplicationAllNamespacesAnnotation = "replication.schrodit.tech/all"
 
+	// ExcludeNamespacesAnnotation is the name of the annotation that defines the namespaces where the annotated resource should never be replicated to.
+	ExcludeNamespacesAnnotation = "exclude-namespaces"
+
+	// SecretReplicationExcludeNamespacesAnnotation is the name of the annotation that defines the namespaces where the annotated resource should never be replicated to.
+	SecretReplicationExcludeNamespacesAnnotation = "replication.schrodit.tech/exclude-namespaces"
+
+	// NamespaceSelectorAnnotation is the name of the annotation that defines a label selector for the namespaces where the annotated resource should be replicated to.
+	NamespaceSelectorAnnotation = "namespace-selector"
+
+	// SecretReplicationNamespaceSelectorAnnotation is the name of the annotation that defines a label selector for the namespaces where the annotated resource should be replicated to.
+	SecretReplicationNamespaceSelectorAnnotation = "replication.schrodit.tech/namespace-selector"
+
+	// PreserveAnnotationsAnnotation is the name of the annotation that defines a comma separated list of annotations
+	// that are copied to the replicas of the annotated resource.
+	PreserveAnnotationsAnnotation = "preserve-annotations"
+
+	// SecretReplicationPreserveAnnotationsAnnotation is the name of the annotation that defines a comma separated list of annotations
+	// that are copied to the replicas of the annotated resource.
+	SecretReplicationPreserveAnnotationsAnnotation = "replication.schrodit.tech/preserve-annotations"
+
+	// TargetNameAnnotation is the name of the annotation that defines the name of the replicas of the annotated resource.
+	TargetNameAnnotation = "target-name"
+
+	// SecretReplicationTargetNameAnnotation is the name of the annotation that defines the name of the replicas of the annotated resource.
+	SecretReplicationTargetNameAnnotation = "replication.schrodit.tech/target-name"
+
+	// KeyMappingAnnotation is the name of the annotation that defines a comma separated list of key renames like "tls.crt=ca.crt"
+	// that are applied to the data of the replicas.
+	KeyMappingAnnotation = "key-mapping"
+
+	// SecretReplicationKeyMappingAnnotation is the name of the annotation that defines a comma separated list of key renames like "tls.crt=ca.crt"
+	// that are applied to the data of the replicas.
+	SecretReplicationKeyMappingAnnotation = "replication.schrodit.tech/key-mapping"
+
+	// IncludeKeysAnnotation is the name of the annotation that defines the data keys that are copied to the replicas.
+	IncludeKeysAnnotation = "include-keys"
+
+	// SecretReplicationIncludeKeysAnnotation is the name of the annotation that defines the data keys that are copied to the replicas.
+	SecretReplicationIncludeKeysAnnotation = "replication.schrodit.tech/include-keys"
+
+	// ExcludeKeysAnnotation is the name of the annotation that defines the data keys that are not copied to the replicas.
+	ExcludeKeysAnnotation = "exclude-keys"
+
+	// SecretReplicationExcludeKeysAnnotation is the name of the annotation that defines the data keys that are not copied to the replicas.
+	SecretReplicationExcludeKeysAnnotation = "replication.schrodit.tech/exclude-keys"
+
+	// ReplicateFromAnnotation is the name of the annotation that defines the source "<namespace>/<name>"
+	// whose data should be pulled into the annotated resource.
+	ReplicateFromAnnotation = "replicate-from"
+
+	// SecretReplicationReplicateFromAnnotation is the name of the annotation that defines the source "<namespace>/<name>"
+	// whose data should be pulled into the annotated resource.
+	SecretReplicationReplicateFromAnnotation = "replication.schrodit.tech/replicate-from"
+
+	// AllowedNamespacesAnnotation is the name of the annotation that defines the namespaces that are allowed to pull the annotated resource.
+	AllowedNamespacesAnnotation = "allowed-namespaces"
+
+	// SecretReplicationAllowedNamespacesAnnotation is the name of the annotation that defines the namespaces that are allowed to pull the annotated resource.
+	SecretReplicationAllowedNamespacesAnnotation = "replication.schrodit.tech/allowed-namespaces"
+
+	// AllowedNamespaceSelectorAnnotation is the name of the annotation that defines a label selector for the namespaces
+	// that are allowed to pull the annotated resource.
+	AllowedNamespaceSelectorAnnotation = "allowed-namespace-selector"
+
+	// SecretReplicationAllowedNamespaceSelectorAnnotation is the name of the annotation that defines a label selector for the namespaces
+	// that are allowed to pull the annotated resource.
+	SecretReplicationAllowedNamespaceSelectorAnnotation = "replication.schrodit.tech/allowed-namespace-selector"
+
+	// ClustersAnnotation is the name of the annotation that defines a comma separated list of remote clusters
+	// where the annotated resource should be replicated to in addition to the local cluster.
+	ClustersAnnotation = "clusters"
+
+	// SecretReplicationClustersAnnotation is the name of the annotation that defines a comma separated list of remote clusters
+	// where the annotated resource should be replicated to in addition to the local cluster.
+	SecretReplicationClustersAnnotation = "replication.schrodit.tech/clusters"
+
+	// ConflictPolicyAnnotation is the name of the annotation that defines how existing objects in target namespaces
+	// that are not replicas of the annotated resource are handled. One of "skip", "adopt" or "overwrite".
+	ConflictPolicyAnnotation = "conflict-policy"
+
+	// SecretReplicationConflictPolicyAnnotation is the name of the annotation that defines how existing objects in target namespaces
+	// that are not replicas of the annotated resource are handled. One of "skip", "adopt" or "overwrite".
+	SecretReplicationConflictPolicyAnnotation = "replication.schrodit.tech/conflict-policy"
+
 	// FromNamespaceAnnotation is the name of the annotation that defines where the defined secret of the ingress should be synced from.
 	FromNamespaceAnnotation = "from-namespace"
 
@@ -33,18 +117,90 @@ var (
 	// SecretReplicationAllNamespacesAnnotations are the names of the annotation that defines that the annotated resource should be replicated to all namespaces.
 	SecretReplicationAllNamespacesAnnotations = NewAnnotationSet(AllNamespacesAnnotation, DefaultAnnotationPrefix)
 
+	// SecretReplicationExcludeNamespacesAnnotations are the names of the annotation that defines the namespaces where the annotated resource should never be replicated to.
+	SecretReplicationExcludeNamespacesAnnotations = NewAnnotationSet(ExcludeNamespacesAnnotation, DefaultAnnotationPrefix)
+
+	// SecretReplicationNamespaceSelectorAnnotations are the names of the annotation that defines a label selector for the namespaces where the annotated resource should be replicated to.
+	SecretReplicationNamespaceSelectorAnnotations = NewAnnotationSet(NamespaceSelectorAnnotation, DefaultAnnotationPrefix)
+
+	// SecretReplicationPreserveAnnotationsAnnotations are the names of the annotation that defines the annotations that are copied to the replicas.
+	SecretReplicationPreserveAnnotationsAnnotations = NewAnnotationSet(PreserveAnnotationsAnnotation, DefaultAnnotationPrefix)
+
+	// SecretReplicationTargetNameAnnotations are the names of the annotation that defines the name of the replicas.
+	SecretReplicationTargetNameAnnotations = NewAnnotationSet(TargetNameAnnotation, DefaultAnnotationPrefix)
+
+	// SecretReplicationKeyMappingAnnotations are the names of the annotation that defines the key renames of the replicas.
+	SecretReplicationKeyMappingAnnotations = NewAnnotationSet(KeyMappingAnnotation, DefaultAnnotationPrefix)
+
+	// SecretReplicationIncludeKeysAnnotations are the names of the annotation that defines the data keys that are copied to the replicas.
+	SecretReplicationIncludeKeysAnnotations = NewAnnotationSet(IncludeKeysAnnotation, DefaultAnnotationPrefix)
+
+	// SecretReplicationExcludeKeysAnnotations are the names of the annotation that defines the data keys that are not copied to the replicas.
+	SecretReplicationExcludeKeysAnnotations = NewAnnotationSet(ExcludeKeysAnnotation, DefaultAnnotationPrefix)
+
+	// SecretReplicationReplicateFromAnnotations are the names of the annotation that defines the source of a pulled resource.
+	SecretReplicationReplicateFromAnnotations = NewAnnotationSet(ReplicateFromAnnotation, DefaultAnnotationPrefix)
+
+	// SecretReplicationAllowedNamespacesAnnotations are the names of the annotation that defines the namespaces that are allowed to pull the annotated resource.
+	SecretReplicationAllowedNamespacesAnnotations = NewAnnotationSet(AllowedNamespacesAnnotation, DefaultAnnotationPrefix)
+
+	// SecretReplicationAllowedNamespaceSelectorAnnotations are the names of the annotation that defines a label selector for the namespaces that are allowed to pull the annotated resource.
+	SecretReplicationAllowedNamespaceSelectorAnnotations = NewAnnotationSet(AllowedNamespaceSelectorAnnotation, DefaultAnnotationPrefix)
+
+	// SecretReplicationClustersAnnotations are the names of the annotation that defines the remote clusters where the annotated resource should be replicated to.
+	SecretReplicationClustersAnnotations = NewAnnotationSet(ClustersAnnotation, DefaultAnnotationPrefix)
+
+	// SecretReplicationConflictPolicyAnnotations are the names of the annotation that defines how conflicting objects in target namespaces are handled.
+	SecretReplicationConflictPolicyAnnotations = NewAnnotationSet(ConflictPolicyAnnotation, DefaultAnnotationPrefix)
+
 	// SecretReplicationFromNamespaceAnnotations are the names of the annotation that defines where the defined secret of the ingress should be synced from.
 	SecretReplicationFromNamespaceAnnotations = NewAnnotationSet(FromNamespaceAnnotation, DefaultAnnotationPrefix)
 )
 
+// UserFacingAnnotationSets returns all user facing annotation sets.
+func UserFacingAnnotationSets() []*AnnotationSet {
+	return []*AnnotationSet{
+		SecretReplicationNamespacesAnnotations,
+		SecretReplicationAllNamespacesAnnotations,
+		SecretReplicationExcludeNamespacesAnnotations,
+		SecretReplicationNamespaceSelectorAnnotations,
+		SecretReplicationPreserveAnnotationsAnnotations,
+		SecretReplicationTargetNameAnnotations,
+		SecretReplicationKeyMappingAnnotations,
+		SecretReplicationIncludeKeysAnnotations,
+		SecretReplicationExcludeKeysAnnotations,
+		SecretReplicationReplicateFromAnnotations,
+		SecretReplicationAllowedNamespacesAnnotations,
+		SecretReplicationAllowedNamespaceSelectorAnnotations,
+		SecretReplicationClustersAnnotations,
+		SecretReplicationConflictPolicyAnnotations,
+		SecretReplicationFromNamespaceAnnotations,
+	}
+}
+
 // SecretReplicationReplicaOfAnnotation is the name of the annotation that defines the source resource of the current resource.
 const SecretReplicationReplicaOfAnnotation = "replication.schrodit.tech/replicaOf"
 
 // SecretReplicationLastHashAnnotation is the name of the annotation that defines the last observed hash of the replicating secret.
 const SecretReplicationLastObservedHashAnnotation = "replication.schrodit.tech/lastObservedHash"
 
+// SecretReplicationReferencedByAnnotationPrefix is the prefix of the annotations that mark the kinds of resources,
+// e.g. ingresses, whose references caused the replication of the current resource.
+// Every kind sets its own annotation like "replication.schrodit.tech/referencedBy.ingress"
+// so that a replica is only deleted when it is not referenced by any kind anymore.
+const SecretReplicationReferencedByAnnotationPrefix = "replication.schrodit.tech/referencedBy."
+
+// SecretReplicationStatusAnnotation is the name of the annotation that contains the replication status of a source resource.
+const SecretReplicationStatusAnnotation = "replication.schrodit.tech/status"
+
+// SecretReplicationFinalizer is the name of the finalizer that is added to replicated source resources
+// so that their replicas can be garbage collected.
+const SecretReplicationFinalizer = "replication.schrodit.tech/finalizer"
+
 const Separator = "/"
 
+// AnnotationSet defines a user facing annotation that can be configured with multiple prefixes.
+// +kubebuilder:object:generate=false
 type AnnotationSet struct {
 	Default string
 	Key     string
@@ -0,0 +1,6 @@
+// Package v1alpha1 contains the user facing annotations and the API types of the replication.schrodit.tech API group.
+// +kubebuilder:object:generate=true
+// +groupName=replication.schrodit.tech
+package v1alpha1
+
+//go:generate controller-gen object:headerFile="../../../../hack/boilerplate.go.txt" paths=. crd:crdVersions=v1 output:crd:artifacts:config=../../../../chart/secret-replication-controller/chart/crds
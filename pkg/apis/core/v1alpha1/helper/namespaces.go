@@ -0,0 +1,81 @@
+package helper
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// NamespaceMatcher matches namespace names against a list of exact names, glob patterns and regular expressions.
+// Glob patterns are entries that contain one of "*", "?" or "[".
+// Regular expressions are entries that are enclosed in slashes like "/^ci-[0-9]+$/".
+type NamespaceMatcher struct {
+	names   sets.String
+	globs   []string
+	regexps []*regexp.Regexp
+}
+
+// ParseNamespaceList parses a comma separated list of namespace names and patterns.
+func ParseNamespaceList(val string) (*NamespaceMatcher, error) {
+	m := &NamespaceMatcher{
+		names: sets.NewString(),
+	}
+	for _, entry := range strings.Split(val, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+
+		if len(entry) > 2 && strings.HasPrefix(entry, "/") && strings.HasSuffix(entry, "/") {
+			re, err := regexp.Compile(entry[1 : len(entry)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid namespace regular expression %q: %w", entry, err)
+			}
+			m.regexps = append(m.regexps, re)
+			continue
+		}
+
+		if strings.ContainsAny(entry, "*?[") {
+			// validate the pattern as path.Match only reports errors when the pattern is matched.
+			if _, err := path.Match(entry, ""); err != nil {
+				return nil, fmt.Errorf("invalid namespace pattern %q: %w", entry, err)
+			}
+			m.globs = append(m.globs, entry)
+			continue
+		}
+
+		m.names.Insert(entry)
+	}
+	return m, nil
+}
+
+// Names returns all exact namespace names of the list.
+func (m *NamespaceMatcher) Names() []string {
+	return m.names.List()
+}
+
+// HasPatterns returns whether the list contains glob patterns or regular expressions.
+func (m *NamespaceMatcher) HasPatterns() bool {
+	return len(m.globs) != 0 || len(m.regexps) != 0
+}
+
+// Matches checks whether the given namespace is matched by one of the names or patterns.
+func (m *NamespaceMatcher) Matches(namespace string) bool {
+	if m.names.Has(namespace) {
+		return true
+	}
+	for _, glob := range m.globs {
+		if ok, _ := path.Match(glob, namespace); ok {
+			return true
+		}
+	}
+	for _, re := range m.regexps {
+		if re.MatchString(namespace) {
+			return true
+		}
+	}
+	return false
+}
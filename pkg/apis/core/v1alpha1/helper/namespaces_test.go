@@ -0,0 +1,45 @@
+package helper_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1/helper"
+)
+
+var _ = Describe("namespace matcher", func() {
+
+	It("should match exact namespace names", func() {
+		m, err := helper.ParseNamespaceList("a, b")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(m.HasPatterns()).To(BeFalse())
+		Expect(m.Names()).To(ConsistOf("a", "b"))
+		Expect(m.Matches("a")).To(BeTrue())
+		Expect(m.Matches("b")).To(BeTrue())
+		Expect(m.Matches("c")).To(BeFalse())
+	})
+
+	It("should match glob patterns", func() {
+		m, err := helper.ParseNamespaceList("team-*")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(m.HasPatterns()).To(BeTrue())
+		Expect(m.Names()).To(BeEmpty())
+		Expect(m.Matches("team-a")).To(BeTrue())
+		Expect(m.Matches("other-team-a")).To(BeFalse())
+	})
+
+	It("should match regular expressions", func() {
+		m, err := helper.ParseNamespaceList("/^ci-[0-9]+$/")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(m.HasPatterns()).To(BeTrue())
+		Expect(m.Matches("ci-123")).To(BeTrue())
+		Expect(m.Matches("ci-abc")).To(BeFalse())
+	})
+
+	It("should fail on invalid patterns", func() {
+		_, err := helper.ParseNamespaceList("/[/")
+		Expect(err).To(HaveOccurred())
+		_, err = helper.ParseNamespaceList("team-[")
+		Expect(err).To(HaveOccurred())
+	})
+})
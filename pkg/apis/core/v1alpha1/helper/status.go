@@ -0,0 +1,36 @@
+package helper
+
+import (
+	"encoding/json"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetReplicationStatus returns the replication status that is stored in the status annotation of the given object.
+func GetReplicationStatus(obj metav1.Object) ([]v1alpha1.ReplicationTargetStatus, error) {
+	val, ok := obj.GetAnnotations()[v1alpha1.SecretReplicationStatusAnnotation]
+	if !ok || len(val) == 0 {
+		return nil, nil
+	}
+	statuses := make([]v1alpha1.ReplicationTargetStatus, 0)
+	if err := json.Unmarshal([]byte(val), &statuses); err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+// SetReplicationStatus stores the replication status in the status annotation of the given object.
+func SetReplicationStatus(obj metav1.Object, statuses []v1alpha1.ReplicationTargetStatus) error {
+	data, err := json.Marshal(statuses)
+	if err != nil {
+		return err
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[v1alpha1.SecretReplicationStatusAnnotation] = string(data)
+	obj.SetAnnotations(annotations)
+	return nil
+}
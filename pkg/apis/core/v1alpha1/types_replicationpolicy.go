@@ -0,0 +1,108 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=rp
+// +kubebuilder:printcolumn:name="Secret",type=string,JSONPath=`.spec.secretName`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ReplicationPolicy defines the replication of a secret in the namespace of the policy to other namespaces.
+// It is an alternative to the replication annotations on the secret itself.
+// Policies can be combined with the annotations and with other policies of the same secret,
+// a replica is only deleted if neither of them targets its namespace anymore.
+type ReplicationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ReplicationPolicySpec `json:"spec"`
+	// +optional
+	Status ReplicationPolicyStatus `json:"status,omitempty"`
+}
+
+// ReplicationPolicySpec defines the source secret and the target namespaces of a replication.
+type ReplicationPolicySpec struct {
+	// SecretName is the name of the secret in the namespace of the policy that should be replicated.
+	SecretName string `json:"secretName"`
+
+	// AllNamespaces defines that the secret should be replicated to all namespaces.
+	// +optional
+	AllNamespaces bool `json:"allNamespaces,omitempty"`
+
+	// Namespaces defines the names of the namespaces the secret should be replicated to.
+	// Glob patterns like "team-*" and regular expressions enclosed in slashes like "/^ci-[0-9]+$/" are supported.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// NamespaceSelector selects the namespaces the secret should be replicated to by their labels.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// ExcludeNamespaces defines the names or patterns of namespaces the secret should never be replicated to.
+	// +optional
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+}
+
+// ReplicationPolicyStatus describes the observed state of a replication policy.
+type ReplicationPolicyStatus struct {
+	// ObservedGeneration is the most recent generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Targets describes the state of the replica in every target namespace.
+	// +optional
+	Targets []ReplicationTargetStatus `json:"targets,omitempty"`
+
+	// Reason describes why the policy could not be replicated,
+	// e.g. because its secret does not exist or its target namespaces cannot be resolved.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is a human readable description of the last failure of the policy.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ReplicationTargetStatus describes the state of a replica in a target namespace.
+type ReplicationTargetStatus struct {
+	// Cluster is the name of the remote cluster of the replica.
+	// The replica is part of the local cluster if no cluster is defined.
+	// +optional
+	Cluster string `json:"cluster,omitempty"`
+
+	// Namespace is the target namespace of the replica.
+	Namespace string `json:"namespace"`
+
+	// LastSyncedHash is the hash of the source data the replica was last synced with.
+	// +optional
+	LastSyncedHash string `json:"lastSyncedHash,omitempty"`
+
+	// LastSyncTime is the time the replica was last created or updated.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// Reason describes why the last replication failed.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is a human readable description of the last replication failure.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ReplicationPolicyList contains a list of replication policies.
+type ReplicationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ReplicationPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ReplicationPolicy{}, &ReplicationPolicyList{})
+}
@@ -0,0 +1,81 @@
+package clusters
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// KubeconfigKey is the data key of cluster secrets that contains the kubeconfig of the remote cluster.
+const KubeconfigKey = "kubeconfig"
+
+// Cache provides clients for remote clusters.
+// Every remote cluster is defined by a secret in the namespace of the controller
+// that has the name of the cluster and contains the kubeconfig of the cluster.
+// Clients are cached per cluster and recreated when the kubeconfig changes.
+type Cache struct {
+	client    client.Client
+	scheme    *runtime.Scheme
+	namespace string
+
+	mux     sync.Mutex
+	clients map[string]cachedClient
+}
+
+type cachedClient struct {
+	hash   string
+	client client.Client
+}
+
+// NewCache creates a new cluster client cache that reads the cluster secrets from the given namespace.
+func NewCache(kubeClient client.Client, scheme *runtime.Scheme, namespace string) *Cache {
+	return &Cache{
+		client:    kubeClient,
+		scheme:    scheme,
+		namespace: namespace,
+		clients:   map[string]cachedClient{},
+	}
+}
+
+// Get returns the client of the remote cluster with the given name.
+func (c *Cache) Get(ctx context.Context, name string) (client.Client, error) {
+	secret := &corev1.Secret{}
+	if err := c.client.Get(ctx, client.ObjectKey{Name: name, Namespace: c.namespace}, secret); err != nil {
+		return nil, fmt.Errorf("unable to get cluster secret %s/%s: %w", c.namespace, name, err)
+	}
+	kubeconfig, ok := secret.Data[KubeconfigKey]
+	if !ok {
+		return nil, fmt.Errorf("cluster secret %s/%s does not contain a %q key", c.namespace, name, KubeconfigKey)
+	}
+
+	h := sha1.New()
+	_, _ = h.Write(kubeconfig)
+	hash := hex.EncodeToString(h.Sum(nil))
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if cached, ok := c.clients[name]; ok && cached.hash == hash {
+		return cached.client, nil
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse kubeconfig of cluster %s: %w", name, err)
+	}
+	remoteClient, err := client.New(restConfig, client.Options{Scheme: c.scheme})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create client for cluster %s: %w", name, err)
+	}
+	c.clients[name] = cachedClient{
+		hash:   hash,
+		client: remoteClient,
+	}
+	return remoteClient, nil
+}
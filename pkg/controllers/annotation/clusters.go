@@ -0,0 +1,128 @@
+package annotationctrl
+
+import (
+	"context"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1/helper"
+	"github.com/schrodit/secret-replication-controller/pkg/clusters"
+	interrors "github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+)
+
+// WithClusters enables the replication to remote clusters that are defined by the clusters annotation.
+func (c *Controller) WithClusters(cache *clusters.Cache) *Controller {
+	c.clusters = cache
+	return c
+}
+
+// clustersFromAnnotations returns the names of the remote clusters of the source.
+func clustersFromAnnotations(src ctrlclient.Object) sets.String {
+	names := sets.NewString()
+	val, ok := helper.GetAnnotation(src, v1alpha1.SecretReplicationClustersAnnotations)
+	if !ok {
+		return names
+	}
+	for _, name := range strings.Split(val, ",") {
+		if name = strings.TrimSpace(name); len(name) != 0 {
+			names.Insert(name)
+		}
+	}
+	return names
+}
+
+// previousClusters returns the names of all remote clusters of the previous replication status.
+func previousClusters(previous []v1alpha1.ReplicationTargetStatus) sets.String {
+	names := sets.NewString()
+	for _, status := range previous {
+		if len(status.Cluster) != 0 {
+			names.Insert(status.Cluster)
+		}
+	}
+	return names
+}
+
+// replicateToClusters replicates the source to the targets in all remote clusters of the source.
+// Replicas in clusters that were replicated to before but are not targeted anymore are deleted.
+func (c *Controller) replicateToClusters(ctx context.Context, src ctrlclient.Object, targets *replicator.Targets, previous []v1alpha1.ReplicationTargetStatus) ([]replicator.Result, error) {
+	if c.clusters == nil {
+		return nil, nil
+	}
+
+	var (
+		names   = clustersFromAnnotations(src)
+		results = make([]replicator.Result, 0)
+		allErrs = interrors.ErrorList{}
+	)
+	for _, name := range names.List() {
+		remoteClient, err := c.clusters.Get(ctx, name)
+		if err != nil {
+			allErrs = append(allErrs, interrors.Error{
+				Src:     src,
+				Reason:  interrors.InvalidCluster,
+				Msg:     "unable to get cluster client",
+				Err:     err,
+				Cluster: name,
+			})
+			continue
+		}
+
+		namespaces, err := targets.Resolve(ctx, remoteClient, src)
+		if err != nil {
+			allErrs = append(allErrs, interrors.WithCluster(err, name))
+			continue
+		}
+
+		r := replicator.New(remoteClient, src)
+		for _, res := range r.ReplicateToAll(ctx, namespaces.List()) {
+			res.Cluster = name
+			res.Err = interrors.WithCluster(res.Err, name)
+			results = append(results, res)
+		}
+		if err := r.DeleteReplicasExcept(ctx, namespaces); err != nil {
+			allErrs = append(allErrs, interrors.WithCluster(err, name))
+		}
+	}
+
+	// remove replicas from clusters that are not targeted anymore
+	if err := c.deleteFromClusters(ctx, src, previousClusters(previous).Difference(names)); err != nil {
+		allErrs = append(allErrs, err)
+	}
+
+	if len(allErrs) == 0 {
+		return results, nil
+	}
+	return results, allErrs
+}
+
+// deleteFromClusters deletes all replicas of the source in the given remote clusters.
+func (c *Controller) deleteFromClusters(ctx context.Context, src ctrlclient.Object, names sets.String) error {
+	if c.clusters == nil {
+		return nil
+	}
+	allErrs := interrors.ErrorList{}
+	for _, name := range names.List() {
+		remoteClient, err := c.clusters.Get(ctx, name)
+		if err != nil {
+			allErrs = append(allErrs, interrors.Error{
+				Src:     src,
+				Reason:  interrors.InvalidCluster,
+				Msg:     "unable to get cluster client to delete replicas",
+				Err:     err,
+				Cluster: name,
+			})
+			continue
+		}
+		if err := replicator.New(remoteClient, src).DeleteReplicasExcept(ctx, sets.NewString()); err != nil {
+			allErrs = append(allErrs, interrors.WithCluster(err, name))
+		}
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return allErrs
+}
@@ -0,0 +1,60 @@
+package annotationctrl
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/clusters"
+	"github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+	"github.com/schrodit/secret-replication-controller/pkg/controllers/predicates"
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// Controller replicates source objects of one kind to the namespaces that are defined by their annotations.
+type Controller struct {
+	log       logr.Logger
+	client    ctrlclient.Client
+	name      string
+	newObject func() ctrlclient.Object
+	clusters  *clusters.Cache
+	*errors.ErrorReporter
+}
+
+// New creates a new controller for the kind of objects that are returned by newObject.
+func New(log logr.Logger, client ctrlclient.Client, eventRecorder record.EventRecorder, newObject func() ctrlclient.Object) *Controller {
+	return &Controller{
+		log:           log,
+		client:        client,
+		name:          replicator.KindName(newObject()),
+		newObject:     newObject,
+		ErrorReporter: errors.NewErrorReporter(eventRecorder),
+	}
+}
+
+// SetupWithManager adds the controller to the given manager
+func (c *Controller) SetupWithManager(mgr manager.Manager, opts controller.Options) error {
+	if err := IndexFields(context.Background(), mgr.GetFieldIndexer(), c.newObject()); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(c.newObject(), builder.WithPredicates(predicates.IgnoreAnnotationChanges(v1alpha1.SecretReplicationStatusAnnotation))).
+		WithOptions(opts).
+		Watches(&source.Kind{Type: c.newObject()},
+			handler.EnqueueRequestsFromMapFunc(c.MapSource)).
+		Watches(&source.Kind{Type: c.newObject()},
+			handler.EnqueueRequestsFromMapFunc(c.MapReplica)).
+		Watches(&source.Kind{Type: &corev1.Namespace{}},
+			handler.EnqueueRequestsFromMapFunc(c.MapNamespace),
+			builder.WithPredicates(predicates.NamespaceCreatedOrLabelsChanged())).
+		Complete(c)
+}
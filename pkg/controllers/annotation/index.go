@@ -0,0 +1,48 @@
+package annotationctrl
+
+import (
+	"context"
+	"fmt"
+
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+)
+
+const (
+	// PullSourceIndex is the name of the field index that maps sources in the format <namespace>/<name>
+	// to the objects that pull the data of the source.
+	PullSourceIndex = "replication.schrodit.tech/pullSource"
+	// ReplicatedIndex is the name of the field index that contains all sources that define replication targets
+	// with the value "true".
+	ReplicatedIndex = "replication.schrodit.tech/replicated"
+)
+
+// IndexFields registers the field indexes that are used by the map functions of the controller
+// for objects of the kind of the given object.
+func IndexFields(ctx context.Context, indexer ctrlclient.FieldIndexer, obj ctrlclient.Object) error {
+	if err := indexer.IndexField(ctx, obj, PullSourceIndex, pullSource); err != nil {
+		return fmt.Errorf("unable to index %ss by pull source: %w", replicator.KindName(obj), err)
+	}
+	if err := indexer.IndexField(ctx, obj, ReplicatedIndex, replicated); err != nil {
+		return fmt.Errorf("unable to index replicated %ss: %w", replicator.KindName(obj), err)
+	}
+	return nil
+}
+
+// pullSource returns the key of the source that is pulled by the object.
+func pullSource(obj ctrlclient.Object) []string {
+	key, ok, err := replicator.PullSource(obj)
+	if err != nil || !ok {
+		return nil
+	}
+	return []string{key.String()}
+}
+
+// replicated returns "true" for sources that define replication targets.
+func replicated(obj ctrlclient.Object) []string {
+	if _, ok, _ := replicator.TargetsFromAnnotations(obj); !ok {
+		return nil
+	}
+	return []string{"true"}
+}
@@ -0,0 +1,56 @@
+package annotationctrl
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+)
+
+// MapNamespace maps a namespace to all source objects that should be replicated into that namespace.
+// Sources that already have a replica in the namespace are mapped as well,
+// so that replicas are removed when the labels of the namespace do not match the source anymore.
+// Only sources that define replication targets are evaluated using the ReplicatedIndex.
+func (c *Controller) MapNamespace(obj ctrlclient.Object) []reconcile.Request {
+	sources, err := replicator.List(context.Background(), c.client, c.newObject(), ctrlclient.MatchingFields{ReplicatedIndex: "true"})
+	if err != nil {
+		c.log.Error(err, "unable to list sources for namespace", "namespace", obj.GetName())
+		return nil
+	}
+
+	keys := map[types.NamespacedName]struct{}{}
+	for _, src := range sources {
+		if targetsNamespace(src, obj) {
+			keys[types.NamespacedName{Name: src.GetName(), Namespace: src.GetNamespace()}] = struct{}{}
+		}
+	}
+
+	replicas, err := replicator.List(context.Background(), c.client, c.newObject(), ctrlclient.InNamespace(obj.GetName()))
+	if err != nil {
+		c.log.Error(err, "unable to list replicas in namespace", "namespace", obj.GetName())
+		return nil
+	}
+	for _, replica := range replicas {
+		if srcKey, ok := replicator.SourceOf(replica); ok {
+			keys[srcKey] = struct{}{}
+		}
+	}
+
+	requests := make([]reconcile.Request, 0, len(keys))
+	for key := range keys {
+		requests = append(requests, reconcile.Request{NamespacedName: key})
+	}
+	return requests
+}
+
+// targetsNamespace checks whether the source should be replicated into the given namespace.
+func targetsNamespace(src ctrlclient.Object, namespace ctrlclient.Object) bool {
+	targets, ok, err := replicator.TargetsFromAnnotations(src)
+	if err != nil || !ok {
+		return false
+	}
+	return targets.Matches(namespace)
+}
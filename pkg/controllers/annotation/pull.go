@@ -0,0 +1,67 @@
+package annotationctrl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	interrors "github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+)
+
+// reconcilePull fills the given object with the data of the source that is defined by its replicate-from annotation.
+func (c *Controller) reconcilePull(ctx context.Context, dst ctrlclient.Object, srcKey types.NamespacedName) error {
+	log := logr.FromContextOrDiscard(ctx)
+	log.V(10).Info("check pull replication", "source", srcKey.String())
+
+	src := c.newObject()
+	if err := c.client.Get(ctx, srcKey, src); err != nil {
+		if apierrors.IsNotFound(err) {
+			return c.Report(ctx, interrors.Error{
+				Src:    dst,
+				Reason: interrors.SourceNotFound,
+				Msg:    fmt.Sprintf("source %s does not exist", srcKey.String()),
+			})
+		}
+		return fmt.Errorf("unable to get source %s: %w", srcKey.String(), err)
+	}
+
+	if err := replicator.AuthorizePull(ctx, c.client, src, dst); err != nil {
+		return c.Report(ctx, err)
+	}
+
+	res, err := replicator.New(c.client, src).ReplicateInto(ctx, dst)
+	res.Err = err
+	replicator.RecordResults(c.name, src, []replicator.Result{res})
+	if err != nil {
+		return c.Report(ctx, err)
+	}
+	if res.Changed() {
+		c.Event(dst, string(res.Action), fmt.Sprintf("%s data from %s", res.Action, srcKey.String()))
+	}
+	return nil
+}
+
+// MapSource maps a source object to all objects that pull the data of that source.
+// The pulling objects are looked up using the PullSourceIndex.
+func (c *Controller) MapSource(obj ctrlclient.Object) []reconcile.Request {
+	objects, err := replicator.List(context.Background(), c.client, c.newObject(),
+		ctrlclient.MatchingFields{PullSourceIndex: replicator.ReplicaOf(obj)})
+	if err != nil {
+		c.log.Error(err, "unable to list pulling objects", "source", replicator.ReplicaOf(obj))
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(objects))
+	for _, o := range objects {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: o.GetName(), Namespace: o.GetNamespace()},
+		})
+	}
+	return requests
+}
@@ -0,0 +1,176 @@
+package annotationctrl
+
+import (
+	"context"
+	"fmt"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/go-logr/logr"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1/helper"
+	interrors "github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+)
+
+func (c *Controller) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	ctx = logr.NewContext(ctx, c.log.WithValues("name", req.Name, "namespace", req.Namespace))
+	src := c.newObject()
+	if err := c.client.Get(ctx, req.NamespacedName, src); err != nil {
+		return reconcile.Result{}, ctrlclient.IgnoreNotFound(err)
+	}
+
+	if !src.GetDeletionTimestamp().IsZero() {
+		if err := c.delete(ctx, src); err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{}, nil
+	}
+
+	srcKey, pull, err := replicator.PullSource(src)
+	if err != nil {
+		return reconcile.Result{}, c.Report(ctx, err)
+	}
+	if pull {
+		return reconcile.Result{}, c.reconcilePull(ctx, src, srcKey)
+	}
+
+	if err := c.reconcile(ctx, src); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+func (c *Controller) reconcile(ctx context.Context, src ctrlclient.Object) error {
+	log := logr.FromContextOrDiscard(ctx)
+	log.V(10).Info("check replication for source")
+	targets, ok, err := replicator.TargetsFromAnnotations(src)
+	if err != nil {
+		return c.Report(ctx, err)
+	}
+	if !ok {
+		if controllerutil.ContainsFinalizer(src, v1alpha1.SecretReplicationFinalizer) {
+			log.V(3).Info("source is not replicated anymore")
+			return c.delete(ctx, src)
+		}
+		log.V(10).Info("source not applicable for replication")
+		return nil
+	}
+
+	if !controllerutil.ContainsFinalizer(src, v1alpha1.SecretReplicationFinalizer) {
+		controllerutil.AddFinalizer(src, v1alpha1.SecretReplicationFinalizer)
+		if err := c.client.Update(ctx, src); err != nil {
+			return fmt.Errorf("unable to add finalizer: %w", err)
+		}
+	}
+
+	var (
+		previous = c.previousStatus(ctx, src)
+		r        = replicator.New(c.client, src)
+		results  = make([]replicator.Result, 0)
+		allErrs  = interrors.ErrorList{}
+	)
+
+	// the targets are resolved per cluster so that namespaces that only exist in a remote cluster
+	// do not block the replication to that cluster.
+	namespaces, err := targets.Resolve(ctx, c.client, src)
+	if err != nil {
+		allErrs = append(allErrs, err)
+	} else {
+		results = r.ReplicateToAll(ctx, namespaces.List())
+	}
+
+	clusterResults, err := c.replicateToClusters(ctx, src, targets, previous)
+	if err != nil {
+		allErrs = append(allErrs, err)
+	}
+	results = append(results, clusterResults...)
+
+	for _, res := range results {
+		if res.Err != nil {
+			allErrs = append(allErrs, res.Err)
+			continue
+		}
+		if res.Changed() {
+			c.Event(src, string(res.Action), res.Message())
+		}
+	}
+	replicator.RecordResults(c.name, src, results)
+	replicator.RecordManagedReplicas(c.name, src, src, results)
+
+	// remove replicas from namespaces that are not targeted anymore.
+	// Replicas are kept if the local targets are unknown.
+	if namespaces != nil {
+		if err := r.DeleteReplicasExcept(ctx, namespaces); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+
+	if err := c.updateStatus(ctx, src, previous, results); err != nil {
+		allErrs = append(allErrs, err)
+	}
+
+	return c.Report(ctx, allErrs)
+}
+
+// previousStatus returns the replication status that is stored in the status annotation of the source.
+func (c *Controller) previousStatus(ctx context.Context, src ctrlclient.Object) []v1alpha1.ReplicationTargetStatus {
+	previous, err := helper.GetReplicationStatus(src)
+	if err != nil {
+		// a corrupted status is simply overwritten
+		logr.FromContextOrDiscard(ctx).V(3).Info("unable to parse replication status", "error", err.Error())
+		return nil
+	}
+	return previous
+}
+
+// updateStatus updates the replication status annotation of the source if it has changed.
+// Only the annotation is patched so that concurrent changes of the source by users are not overwritten.
+func (c *Controller) updateStatus(ctx context.Context, src ctrlclient.Object, previous []v1alpha1.ReplicationTargetStatus, results []replicator.Result) error {
+	statuses := replicator.TargetStatuses(src, previous, results)
+	if apiequality.Semantic.DeepEqual(previous, statuses) {
+		return nil
+	}
+
+	patch := ctrlclient.MergeFrom(src.DeepCopyObject().(ctrlclient.Object))
+	if err := helper.SetReplicationStatus(src, statuses); err != nil {
+		return fmt.Errorf("unable to encode replication status: %w", err)
+	}
+	if err := c.client.Patch(ctx, src, patch); err != nil {
+		return fmt.Errorf("unable to update replication status: %w", err)
+	}
+	return nil
+}
+
+// delete removes all replicas of the source and removes the finalizer.
+func (c *Controller) delete(ctx context.Context, src ctrlclient.Object) error {
+	if !controllerutil.ContainsFinalizer(src, v1alpha1.SecretReplicationFinalizer) {
+		return nil
+	}
+
+	if err := replicator.New(c.client, src).DeleteReplicasExcept(ctx, sets.NewString()); err != nil {
+		return c.Report(ctx, err)
+	}
+
+	// replicas in remote clusters are deleted on a best effort basis
+	// so that an unreachable cluster does not block the deletion of the source.
+	remoteClusters := clustersFromAnnotations(src).Union(previousClusters(c.previousStatus(ctx, src)))
+	if err := c.deleteFromClusters(ctx, src, remoteClusters); err != nil {
+		_ = c.Report(ctx, err)
+	}
+
+	replicator.ForgetManagedReplicas(c.name, src, src)
+
+	controllerutil.RemoveFinalizer(src, v1alpha1.SecretReplicationFinalizer)
+	annotations := src.GetAnnotations()
+	delete(annotations, v1alpha1.SecretReplicationStatusAnnotation)
+	src.SetAnnotations(annotations)
+	if err := c.client.Update(ctx, src); err != nil {
+		return fmt.Errorf("unable to remove finalizer: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package annotationctrl
+
+import (
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+)
+
+// MapReplica maps a replica to its source so that changes of the replica's data are detected and reverted.
+func (c *Controller) MapReplica(obj ctrlclient.Object) []reconcile.Request {
+	srcKey, ok := replicator.SourceOf(obj)
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: srcKey}}
+}
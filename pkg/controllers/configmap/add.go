@@ -0,0 +1,27 @@
+package configmapctrl
+
+import (
+	"github.com/go-logr/logr"
+	"github.com/schrodit/secret-replication-controller/pkg/clusters"
+	annotationctrl "github.com/schrodit/secret-replication-controller/pkg/controllers/annotation"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// New creates a new controller that replicates annotated configmaps.
+func New(log logr.Logger, client ctrlclient.Client, eventRecorder record.EventRecorder) *annotationctrl.Controller {
+	return annotationctrl.New(log, client, eventRecorder, func() ctrlclient.Object {
+		return &corev1.ConfigMap{}
+	})
+}
+
+// AddToMgr adds the configmap reconiler to the given manager.
+// Sources are also replicated to remote clusters if a cluster cache is given.
+func AddToMgr(log logr.Logger, mgr manager.Manager, clusterCache *clusters.Cache, opts controller.Options) error {
+	return New(log, mgr.GetClient(), mgr.GetEventRecorderFor("SecretReplicationConfigMapController")).
+		WithClusters(clusterCache).
+		SetupWithManager(mgr, opts)
+}
@@ -0,0 +1,175 @@
+package configmapctrl
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1/helper"
+	annotationctrl "github.com/schrodit/secret-replication-controller/pkg/controllers/annotation"
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("controller", func() {
+
+	var (
+		ctrl       *annotationctrl.Controller
+		configMap  *corev1.ConfigMap
+		namespaces []string
+	)
+
+	BeforeEach(func() {
+		configMap = &corev1.ConfigMap{}
+		configMap.GenerateName = "e2e-"
+		configMap.Namespace = "default"
+		configMap.Data = map[string]string{
+			"key": "value",
+		}
+		configMap.BinaryData = map[string][]byte{
+			"binary": {0x00, 0x01},
+		}
+
+		Expect(client.Create(context.TODO(), configMap)).To(Succeed())
+		namespaces = make([]string, 0)
+
+		ctrl = New(logr.Discard(), client, record.NewFakeRecorder(1024))
+	})
+
+	AfterEach(func() {
+		ctx := context.Background()
+		// remove the finalizer so that the source configmap is actually deleted
+		if err := client.Get(ctx, types.NamespacedName{Name: configMap.Name, Namespace: configMap.Namespace}, configMap); err == nil {
+			controllerutil.RemoveFinalizer(configMap, v1alpha1.SecretReplicationFinalizer)
+			Expect(client.Update(ctx, configMap)).To(Succeed())
+			Expect(ctrlclient.IgnoreNotFound(client.Delete(ctx, configMap))).To(Succeed())
+		}
+
+		for _, ns := range namespaces {
+			namespace := &corev1.Namespace{}
+			namespace.Name = ns
+			Expect(client.Delete(ctx, namespace)).To(Succeed())
+		}
+	})
+
+	It("should create a replicated configmap in one namespace", func() {
+		ctx := context.Background()
+
+		By("create test namespace")
+		ns := &corev1.Namespace{}
+		ns.GenerateName = "e2e-"
+		Expect(client.Create(ctx, ns)).To(Succeed())
+		namespaces = append(namespaces, ns.Name)
+
+		configMap.Annotations = map[string]string{
+			v1alpha1.SecretReplicationNamespacesAnnotation: ns.Name,
+		}
+		Expect(client.Update(ctx, configMap)).To(Succeed())
+
+		_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: configMap.Name, Namespace: configMap.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		newConfigMap := &corev1.ConfigMap{}
+		Expect(client.Get(ctx, types.NamespacedName{Name: configMap.Name, Namespace: ns.Name}, newConfigMap)).To(Succeed())
+
+		Expect(newConfigMap.Data).To(Equal(configMap.Data))
+		Expect(newConfigMap.BinaryData).To(Equal(configMap.BinaryData))
+		Expect(newConfigMap.Annotations).To(HaveKey(v1alpha1.SecretReplicationLastObservedHashAnnotation))
+	})
+
+	It("should update the replica if the data of the configmap changes", func() {
+		ctx := context.Background()
+
+		By("create test namespace")
+		ns := &corev1.Namespace{}
+		ns.GenerateName = "e2e-"
+		Expect(client.Create(ctx, ns)).To(Succeed())
+		namespaces = append(namespaces, ns.Name)
+
+		configMap.Annotations = map[string]string{
+			v1alpha1.SecretReplicationNamespacesAnnotation: ns.Name,
+		}
+		Expect(client.Update(ctx, configMap)).To(Succeed())
+
+		_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: configMap.Name, Namespace: configMap.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(client.Get(ctx, types.NamespacedName{Name: configMap.Name, Namespace: configMap.Namespace}, configMap)).To(Succeed())
+		configMap.Data["key"] = "other"
+		Expect(client.Update(ctx, configMap)).To(Succeed())
+
+		_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: configMap.Name, Namespace: configMap.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		newConfigMap := &corev1.ConfigMap{}
+		Expect(client.Get(ctx, types.NamespacedName{Name: configMap.Name, Namespace: ns.Name}, newConfigMap)).To(Succeed())
+		Expect(newConfigMap.Data).To(HaveKeyWithValue("key", "other"))
+	})
+
+	It("should report existing configmaps that are not replicas as skipped in the status", func() {
+		ctx := context.Background()
+
+		By("create test namespace with an unmanaged configmap")
+		ns := &corev1.Namespace{}
+		ns.GenerateName = "e2e-"
+		Expect(client.Create(ctx, ns)).To(Succeed())
+		namespaces = append(namespaces, ns.Name)
+
+		existing := &corev1.ConfigMap{}
+		existing.Name = configMap.Name
+		existing.Namespace = ns.Name
+		existing.Data = map[string]string{
+			"key": "unmanaged",
+		}
+		Expect(client.Create(ctx, existing)).To(Succeed())
+
+		configMap.Annotations = map[string]string{
+			v1alpha1.SecretReplicationNamespacesAnnotation: ns.Name,
+		}
+		Expect(client.Update(ctx, configMap)).To(Succeed())
+
+		_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: configMap.Name, Namespace: configMap.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(client.Get(ctx, types.NamespacedName{Name: configMap.Name, Namespace: configMap.Namespace}, configMap)).To(Succeed())
+		statuses, err := helper.GetReplicationStatus(configMap)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(statuses).To(HaveLen(1))
+		Expect(statuses[0].Reason).To(Equal(string(replicator.Skipped)))
+		Expect(statuses[0].Message).To(Equal("a configmap that is not a replica of the source already exists"))
+	})
+
+	It("should delete the replicas if the configmap is deleted", func() {
+		ctx := context.Background()
+
+		By("create test namespace")
+		ns := &corev1.Namespace{}
+		ns.GenerateName = "e2e-"
+		Expect(client.Create(ctx, ns)).To(Succeed())
+		namespaces = append(namespaces, ns.Name)
+
+		configMap.Annotations = map[string]string{
+			v1alpha1.SecretReplicationNamespacesAnnotation: ns.Name,
+		}
+		Expect(client.Update(ctx, configMap)).To(Succeed())
+
+		_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: configMap.Name, Namespace: configMap.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(client.Delete(ctx, configMap)).To(Succeed())
+		_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: configMap.Name, Namespace: configMap.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		newConfigMap := &corev1.ConfigMap{}
+		err = client.Get(ctx, types.NamespacedName{Name: configMap.Name, Namespace: ns.Name}, newConfigMap)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
@@ -39,12 +39,18 @@ type Error struct {
 	Msg string
 	// Err defines a optional wrapped error
 	Err error
+	// Cluster defines the remote cluster where the error occurred.
+	// Empty for errors in the local cluster.
+	Cluster string
 }
 
 var _ error = Error{}
 
 func (e Error) Error() string {
 	errMsg := e.Msg
+	if len(e.Cluster) != 0 {
+		errMsg = fmt.Sprintf("cluster %s: %s", e.Cluster, errMsg)
+	}
 	if e.Err != nil {
 		errMsg = fmt.Sprintf("%s: %s", errMsg, e.Err.Error())
 	}
@@ -54,3 +60,26 @@ func (e Error) Error() string {
 func (e Error) Unwrap() error {
 	return e.Err
 }
+
+// WithCluster marks all errors of a possibly nested error list as errors of the given remote cluster.
+// Errors of unknown type are wrapped with the name of the cluster.
+// The destination of the errors is removed as events cannot be recorded for objects of remote clusters.
+func WithCluster(err error, cluster string) error {
+	if err == nil {
+		return nil
+	}
+	switch e := err.(type) {
+	case ErrorList:
+		errs := make(ErrorList, len(e))
+		for i, err := range e {
+			errs[i] = WithCluster(err, cluster)
+		}
+		return errs
+	case Error:
+		e.Cluster = cluster
+		e.Dst = nil
+		return e
+	default:
+		return fmt.Errorf("cluster %s: %w", cluster, err)
+	}
+}
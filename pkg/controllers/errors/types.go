@@ -9,6 +9,24 @@ const (
 	CreateError Reason = "CreateError"
 	// UpdateError defines an error that occurred when a replicated secret could not be updated
 	UpdateError Reason = "UpdateError"
+	// DeleteError defines an error that occurred when a replicated secret could not be deleted
+	DeleteError Reason = "DeleteError"
 	// InvalidNamespace defines an error reason that is thrown when a namespaces does not exist or cannot be validated
 	InvalidNamespace Reason = "InvalidNamespace"
+	// InvalidNamespaceSelector defines an error reason that is thrown when a namespace selector cannot be parsed
+	InvalidNamespaceSelector Reason = "InvalidNamespaceSelector"
+	// InvalidKeyMapping defines an error reason that is thrown when the key projection annotations of a source cannot be parsed
+	InvalidKeyMapping Reason = "InvalidKeyMapping"
+	// InvalidSource defines an error reason that is thrown when the source of a pulled resource cannot be parsed
+	InvalidSource Reason = "InvalidSource"
+	// Forbidden defines an error reason that is thrown when a namespace is not allowed to replicate a source
+	Forbidden Reason = "Forbidden"
+	// InvalidCluster defines an error reason that is thrown when the client of a remote cluster cannot be created
+	InvalidCluster Reason = "InvalidCluster"
+	// Conflict defines an error reason that is thrown when an object with the name of a replica exists that is not a replica of the source
+	Conflict Reason = "Conflict"
+	// InvalidConflictPolicy defines an error reason that is thrown when the conflict policy of a source is unknown
+	InvalidConflictPolicy Reason = "InvalidConflictPolicy"
+	// SourceNotFound defines an error reason that is thrown when the source of a replication policy or a pulled resource does not exist
+	SourceNotFound Reason = "SourceNotFound"
 )
@@ -7,6 +7,7 @@ import (
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 )
 
@@ -24,13 +25,23 @@ func AggregateMultiError(errs []error) error {
 	return errors.New(errMsg)
 }
 
+// ReasonOf returns the reason of the given error.
+// Errors of unknown type are internal errors.
+func ReasonOf(err error) Reason {
+	var intErr Error
+	if errors.As(err, &intErr) {
+		return intErr.Reason
+	}
+	return InternalError
+}
+
 // ReportErrors reports all errors of a known internal type as events.
 // unknown errors are logged.
 func ReportErrors(ctx context.Context, log logr.Logger, eventRecorder record.EventRecorder, err error) error {
-	allErrs := ErrorList{err}
-	if errs, ok := err.(ErrorList); ok {
-		allErrs = errs
+	if err == nil {
+		return nil
 	}
+	allErrs := flatten(err)
 
 	reportErrs := ErrorList{}
 	for _, err := range allErrs {
@@ -41,16 +52,37 @@ func ReportErrors(ctx context.Context, log logr.Logger, eventRecorder record.Eve
 			log.Error(err, "")
 			continue
 		}
-		log.Error(intErr.Err, intErr.Msg)
-		eventRecorder.Event(intErr.Src, corev1.EventTypeWarning, string(intErr.Reason), intErr.Msg)
+		msg := intErr.Msg
+		if len(intErr.Cluster) != 0 {
+			msg = fmt.Sprintf("cluster %s: %s", intErr.Cluster, msg)
+		}
+		log.Error(intErr.Err, msg)
+		eventRecorder.Event(intErr.Src, corev1.EventTypeWarning, string(intErr.Reason), msg)
 		if intErr.Dst != nil {
-			eventRecorder.Event(intErr.Dst, corev1.EventTypeWarning, string(intErr.Reason), intErr.Msg)
+			eventRecorder.Event(intErr.Dst, corev1.EventTypeWarning, string(intErr.Reason), msg)
 		}
 	}
 
 	return reportErrs.AggregateError()
 }
 
+// flatten returns all errors of a possibly nested error list.
+// Nil errors are omitted.
+func flatten(err error) ErrorList {
+	if err == nil {
+		return nil
+	}
+	errs, ok := err.(ErrorList)
+	if !ok {
+		return ErrorList{err}
+	}
+	allErrs := ErrorList{}
+	for _, err := range errs {
+		allErrs = append(allErrs, flatten(err)...)
+	}
+	return allErrs
+}
+
 // ErrorReporter is a struct that reports aggreagted errors.
 // Is basically a simple wrapper for ReportErrors.
 type ErrorReporter struct {
@@ -68,3 +100,8 @@ func (er ErrorReporter) Report(ctx context.Context, err error) error {
 	log := logr.FromContextOrDiscard(ctx)
 	return ReportErrors(ctx, log, er.recorder, err)
 }
+
+// Event records a normal event with the given reason and message for the object.
+func (er ErrorReporter) Event(obj runtime.Object, reason, msg string) {
+	er.recorder.Event(obj, corev1.EventTypeNormal, reason, msg)
+}
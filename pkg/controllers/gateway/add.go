@@ -0,0 +1,123 @@
+package gatewayctrl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+)
+
+// GatewayGroup is the api group of the gateway api.
+const GatewayGroup = "gateway.networking.k8s.io"
+
+// referrerKind is the kind that marks replicas that have been created for gateways.
+const referrerKind = "gateway"
+
+// supportedVersions are the gateway api versions ordered by preference.
+var supportedVersions = []string{"v1", "v1beta1", "v1alpha2"}
+
+// GatewayController replicates the tls certificates that are referenced by gateways.
+// Gateways are handled as unstructured objects so that the controller does not depend on a specific gateway api release.
+type GatewayController struct {
+	log     logr.Logger
+	client  ctrlclient.Client
+	version schema.GroupVersion
+	*errors.ErrorReporter
+}
+
+// New creates a new gateway controller that reconciles gateways of the given api version.
+func New(log logr.Logger, client ctrlclient.Client, eventRecorder record.EventRecorder, version schema.GroupVersion) *GatewayController {
+	return &GatewayController{
+		log:           log,
+		client:        client,
+		version:       version,
+		ErrorReporter: errors.NewErrorReporter(eventRecorder),
+	}
+}
+
+// AddToMgr adds the gateway reconciler to the given manager.
+// The controller is not started if the cluster does not serve the gateway api.
+func AddToMgr(log logr.Logger, mgr ctrl.Manager, opts controller.Options) error {
+	version, ok, err := DetectGatewayVersion(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		log.Info("Gateway api is not served by the cluster. Skipping gateway controller")
+		return nil
+	}
+	log.Info(fmt.Sprintf("Using gateway api version %q", version.String()))
+
+	c := New(log, mgr.GetClient(), mgr.GetEventRecorderFor("SecretReplicationGatewayController"), version)
+	if err := IndexFields(context.Background(), mgr.GetFieldIndexer(), version); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(c.newGateway()).
+		Watches(&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(c.MapSecret)).
+		WithOptions(opts).
+		Complete(c)
+}
+
+// IndexFields indexes the gateways of the given api version by the source secrets they reference, see replicator.SourceSecretIndex.
+func IndexFields(ctx context.Context, indexer ctrlclient.FieldIndexer, version schema.GroupVersion) error {
+	gateway := &unstructured.Unstructured{}
+	gateway.SetGroupVersionKind(version.WithKind("Gateway"))
+	if err := indexer.IndexField(ctx, gateway, replicator.SourceSecretIndex, sourceSecrets); err != nil {
+		return fmt.Errorf("unable to index gateways by source secret: %w", err)
+	}
+	return nil
+}
+
+// DetectGatewayVersion uses the discovery api to determine the preferred gateway api version that is served by the cluster.
+// Returns false if the cluster does not serve gateways.
+func DetectGatewayVersion(config *rest.Config) (schema.GroupVersion, bool, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return schema.GroupVersion{}, false, fmt.Errorf("unable to create discovery client: %w", err)
+	}
+
+	for _, v := range supportedVersions {
+		version := schema.GroupVersion{Group: GatewayGroup, Version: v}
+		resources, err := discoveryClient.ServerResourcesForGroupVersion(version.String())
+		if err != nil {
+			// the group version is not served by the cluster
+			continue
+		}
+		for _, resource := range resources.APIResources {
+			if resource.Name == "gateways" {
+				return version, true, nil
+			}
+		}
+	}
+	return schema.GroupVersion{}, false, nil
+}
+
+// newGateway creates an empty unstructured gateway of the configured version.
+func (c *GatewayController) newGateway() *unstructured.Unstructured {
+	gateway := &unstructured.Unstructured{}
+	gateway.SetGroupVersionKind(c.version.WithKind("Gateway"))
+	return gateway
+}
+
+// newGatewayList creates an empty unstructured gateway list of the configured version.
+func (c *GatewayController) newGatewayList() *unstructured.UnstructuredList {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(c.version.WithKind("GatewayList"))
+	return list
+}
@@ -0,0 +1,76 @@
+package gatewayctrl_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	gatewayctrl "github.com/schrodit/secret-replication-controller/pkg/controllers/gateway"
+)
+
+func TestSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "gateway controller test suite")
+}
+
+var (
+	testenv *envtest.Environment
+	client  ctrlclient.Client
+	// cachedClient reads from an informer cache with the field indexes of the controller
+	// like the client of a manager.
+	cachedClient ctrlclient.Client
+	stopCache    context.CancelFunc
+)
+
+var _ = BeforeSuite(func() {
+	testenv = &envtest.Environment{
+		CRDDirectoryPaths: []string{filepath.Join("testdata", "crds")},
+	}
+
+	restConfig, err := testenv.Start()
+	Expect(err).ToNot(HaveOccurred())
+
+	client, err = ctrlclient.New(restConfig, ctrlclient.Options{})
+	Expect(err).ToNot(HaveOccurred())
+
+	version, ok, err := gatewayctrl.DetectGatewayVersion(restConfig)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(ok).To(BeTrue())
+	informers, err := cache.New(restConfig, cache.Options{})
+	Expect(err).ToNot(HaveOccurred())
+	var ctx context.Context
+	ctx, stopCache = context.WithCancel(context.Background())
+	Expect(gatewayctrl.IndexFields(ctx, informers, version)).To(Succeed())
+	go func() {
+		defer GinkgoRecover()
+		Expect(informers.Start(ctx)).To(Succeed())
+	}()
+	Expect(informers.WaitForCacheSync(ctx)).To(BeTrue())
+	cachedClient, err = ctrlclient.NewDelegatingClient(ctrlclient.NewDelegatingClientInput{
+		CacheReader: informers,
+		Client:      client,
+	})
+	Expect(err).ToNot(HaveOccurred())
+})
+
+var _ = AfterSuite(func() {
+	stopCache()
+	Expect(testenv.Stop()).To(Succeed())
+})
+
+// expectCached waits until the cached client has observed the given version of the object.
+func expectCached(obj ctrlclient.Object) {
+	cached := obj.DeepCopyObject().(ctrlclient.Object)
+	Eventually(func() string {
+		if err := cachedClient.Get(context.Background(), ctrlclient.ObjectKeyFromObject(obj), cached); err != nil {
+			return ""
+		}
+		return cached.GetResourceVersion()
+	}).Should(Equal(obj.GetResourceVersion()))
+}
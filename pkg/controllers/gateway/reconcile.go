@@ -0,0 +1,164 @@
+package gatewayctrl
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/go-logr/logr"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1/helper"
+	interrors "github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+)
+
+func (c *GatewayController) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	ctx = logr.NewContext(ctx, c.log.WithValues("name", req.Name, "namespace", req.Namespace))
+	gateway := c.newGateway()
+	if err := c.client.Get(ctx, req.NamespacedName, gateway); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		// the gateway has been deleted so its replicas may not be needed anymore
+		return reconcile.Result{}, c.deleteUnreferencedReplicas(ctx, req.Namespace)
+	}
+
+	if err := c.reconcile(ctx, gateway); err != nil {
+		return reconcile.Result{}, err
+	}
+	// the certificates of the gateway may have changed
+	if err := c.deleteUnreferencedReplicas(ctx, req.Namespace); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+func (c *GatewayController) reconcile(ctx context.Context, gateway *unstructured.Unstructured) error {
+	log := logr.FromContextOrDiscard(ctx)
+	log.V(10).Info("check replication for gateway")
+	srcNamespace, ok := helper.GetAnnotation(gateway, v1alpha1.SecretReplicationFromNamespaceAnnotations)
+	if !ok {
+		log.V(10).Info("gateway not applicable for replication")
+		return nil
+	}
+
+	usedSecrets := getSecretsFromGateway(gateway)
+	if len(usedSecrets) == 0 {
+		log.V(10).Info("no secrets used by gateway")
+		return nil
+	}
+
+	// check if defined namespace exists
+	if err := c.client.Get(ctx, client.ObjectKey{Name: srcNamespace}, &corev1.Namespace{}); err != nil {
+		return c.Report(ctx, fmt.Errorf("namespace %q not found", srcNamespace))
+	}
+	allErrs := interrors.ErrorList{}
+	results := replicator.ReplicateReferencedSecrets(ctx, c.client, gateway, srcNamespace, usedSecrets)
+	for i, res := range results {
+		if res.Err != nil {
+			allErrs = append(allErrs, res.Err)
+			continue
+		}
+		if res.Changed() {
+			c.Event(gateway, string(res.Action), fmt.Sprintf("%s replica of secret %s/%s", res.Action, srcNamespace, usedSecrets[i]))
+		}
+	}
+
+	replicator.RecordResults("gateway", &corev1.Secret{}, results)
+
+	return c.Report(ctx, allErrs)
+}
+
+// deleteUnreferencedReplicas releases all secrets in the namespace that have been replicated for gateways
+// but are not referenced by any gateway of the namespace anymore.
+// Replicas that are still referenced by other kinds are kept, see replicator.ReleaseReplica.
+func (c *GatewayController) deleteUnreferencedReplicas(ctx context.Context, namespace string) error {
+	list := c.newGatewayList()
+	if err := c.client.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("unable to list gateways in namespace %q: %w", namespace, err)
+	}
+	referenced := sets.NewString()
+	for i := range list.Items {
+		if !list.Items[i].GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		referenced.Insert(sourceSecrets(&list.Items[i])...)
+	}
+	if err := replicator.ReleaseUnreferencedReplicas(ctx, c.client, referrerKind, namespace, referenced); err != nil {
+		return c.Report(ctx, err)
+	}
+	return nil
+}
+
+// MapSecret maps a secret to all gateways that reference the secret as source of a certificate.
+// Replicas are mapped to the gateways that reference their source so that deleted or changed replicas are restored.
+func (c *GatewayController) MapSecret(obj client.Object) []reconcile.Request {
+	requests := make([]reconcile.Request, 0)
+	for _, key := range replicator.SourceKeys(obj) {
+		list := c.newGatewayList()
+		if err := c.client.List(context.Background(), list, client.MatchingFields{replicator.SourceSecretIndex: key}); err != nil {
+			c.log.Error(err, "unable to list gateways for secret", "secret", key)
+			return nil
+		}
+		for _, gateway := range list.Items {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: gateway.GetName(), Namespace: gateway.GetNamespace()},
+			})
+		}
+	}
+	return requests
+}
+
+// sourceSecrets returns the keys of all source secrets that are referenced by the gateway
+// in the format <namespace>/<name>.
+// It is also used as index function of the replicator.SourceSecretIndex.
+func sourceSecrets(obj client.Object) []string {
+	gateway, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil
+	}
+	return replicator.ReferencedSources(gateway, getSecretsFromGateway(gateway))
+}
+
+// getSecretsFromGateway returns the names of all secrets that are referenced by the tls configuration of the gateway listeners.
+// References to other kinds than core secrets and references with an explicit namespace are ignored
+// as the latter are resolved by the gateway implementation using ReferenceGrants.
+func getSecretsFromGateway(gateway *unstructured.Unstructured) []string {
+	secrets := sets.NewString()
+	listeners, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "listeners")
+	for _, l := range listeners {
+		listener, ok := l.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		refs, _, _ := unstructured.NestedSlice(listener, "tls", "certificateRefs")
+		for _, r := range refs {
+			ref, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			group, _, _ := unstructured.NestedString(ref, "group")
+			kind, found, _ := unstructured.NestedString(ref, "kind")
+			if !found {
+				kind = "Secret"
+			}
+			if len(group) != 0 || kind != "Secret" {
+				continue
+			}
+			if namespace, _, _ := unstructured.NestedString(ref, "namespace"); len(namespace) != 0 && namespace != gateway.GetNamespace() {
+				continue
+			}
+			if name, _, _ := unstructured.NestedString(ref, "name"); len(name) != 0 {
+				secrets.Insert(name)
+			}
+		}
+	}
+	return secrets.List()
+}
@@ -0,0 +1,231 @@
+package gatewayctrl_test
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	gatewayctrl "github.com/schrodit/secret-replication-controller/pkg/controllers/gateway"
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("controller", func() {
+
+	var (
+		ctrl       reconcile.Reconciler
+		version    schema.GroupVersion
+		secret     *corev1.Secret
+		namespaces []string
+	)
+
+	BeforeEach(func() {
+		secret = &corev1.Secret{}
+		secret.GenerateName = "e2e-"
+		secret.Namespace = "default"
+		secret.Annotations = map[string]string{
+			v1alpha1.SecretReplicationAllowedNamespacesAnnotation: "e2e-*",
+		}
+		secret.Data = map[string][]byte{
+			"key": []byte("value"),
+		}
+
+		Expect(client.Create(context.TODO(), secret)).To(Succeed())
+		namespaces = make([]string, 0)
+
+		var (
+			ok  bool
+			err error
+		)
+		version, ok, err = gatewayctrl.DetectGatewayVersion(testenv.Config)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		ctrl = gatewayctrl.New(logr.Discard(), client, record.NewFakeRecorder(1024), version)
+	})
+
+	AfterEach(func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		Expect(client.Delete(ctx, secret)).To(Succeed())
+
+		for _, ns := range namespaces {
+			namespace := &corev1.Namespace{}
+			namespace.Name = ns
+			Expect(client.Delete(ctx, namespace)).To(Succeed())
+		}
+	})
+
+	It("should replicate the certificate of a gateway listener", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+
+		ns := &corev1.Namespace{}
+		ns.GenerateName = "e2e-"
+		Expect(client.Create(ctx, ns)).To(Succeed())
+		namespaces = append(namespaces, ns.Name)
+
+		gateway := tlsGateway(ns.Name, map[string]interface{}{
+			"kind": "Secret",
+			"name": secret.Name,
+		})
+		gateway.SetAnnotations(map[string]string{
+			v1alpha1.SecretReplicationFromNamespaceAnnotation: secret.Namespace,
+		})
+		Expect(client.Create(ctx, gateway)).To(Succeed())
+
+		_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: gateway.GetName(), Namespace: gateway.GetNamespace()}})
+		Expect(err).ToNot(HaveOccurred())
+
+		newSecret := &corev1.Secret{}
+		Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
+		Expect(newSecret.Data).To(Equal(secret.Data))
+	})
+
+	It("should delete a replicated certificate when the gateway is deleted", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+
+		ns := &corev1.Namespace{}
+		ns.GenerateName = "e2e-"
+		Expect(client.Create(ctx, ns)).To(Succeed())
+		namespaces = append(namespaces, ns.Name)
+
+		gateway := tlsGateway(ns.Name, map[string]interface{}{
+			"kind": "Secret",
+			"name": secret.Name,
+		})
+		gateway.SetAnnotations(map[string]string{
+			v1alpha1.SecretReplicationFromNamespaceAnnotation: secret.Namespace,
+		})
+		Expect(client.Create(ctx, gateway)).To(Succeed())
+
+		_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: gateway.GetName(), Namespace: gateway.GetNamespace()}})
+		Expect(err).ToNot(HaveOccurred())
+
+		newSecret := &corev1.Secret{}
+		Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
+		Expect(replicator.Referrers(newSecret).List()).To(ConsistOf("gateway"))
+
+		Expect(client.Delete(ctx, gateway)).To(Succeed())
+		_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: gateway.GetName(), Namespace: gateway.GetNamespace()}})
+		Expect(err).ToNot(HaveOccurred())
+
+		err = client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should map source secrets and their replicas to the gateways that reference them", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+
+		ns := &corev1.Namespace{}
+		ns.GenerateName = "e2e-"
+		Expect(client.Create(ctx, ns)).To(Succeed())
+		namespaces = append(namespaces, ns.Name)
+
+		gateway := tlsGateway(ns.Name, map[string]interface{}{
+			"kind": "Secret",
+			"name": secret.Name,
+		})
+		gateway.SetAnnotations(map[string]string{
+			v1alpha1.SecretReplicationFromNamespaceAnnotation: secret.Namespace,
+		})
+		Expect(client.Create(ctx, gateway)).To(Succeed())
+		expectCached(gateway)
+
+		_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: gateway.GetName(), Namespace: gateway.GetNamespace()}})
+		Expect(err).ToNot(HaveOccurred())
+		newSecret := &corev1.Secret{}
+		Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
+
+		mapCtrl := gatewayctrl.New(logr.Discard(), cachedClient, record.NewFakeRecorder(1024), version)
+		expected := reconcile.Request{NamespacedName: types.NamespacedName{Name: gateway.GetName(), Namespace: gateway.GetNamespace()}}
+		Expect(mapCtrl.MapSecret(secret)).To(ConsistOf(expected))
+		Expect(mapCtrl.MapSecret(newSecret)).To(ConsistOf(expected))
+
+		unrelated := &corev1.Secret{}
+		unrelated.Name = "unrelated"
+		unrelated.Namespace = ns.Name
+		Expect(mapCtrl.MapSecret(unrelated)).To(BeEmpty())
+	})
+
+	It("should succeed if there are no replicas to release", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+
+		ns := &corev1.Namespace{}
+		ns.GenerateName = "e2e-"
+		Expect(client.Create(ctx, ns)).To(Succeed())
+		namespaces = append(namespaces, ns.Name)
+
+		gateway := tlsGateway(ns.Name, map[string]interface{}{
+			"kind": "Secret",
+			"name": secret.Name,
+		})
+		Expect(client.Create(ctx, gateway)).To(Succeed())
+
+		_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: gateway.GetName(), Namespace: gateway.GetNamespace()}})
+		Expect(err).ToNot(HaveOccurred())
+
+		By("reconcile a deleted gateway")
+		_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "deleted", Namespace: ns.Name}})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should ignore certificate references with an explicit namespace", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+
+		ns := &corev1.Namespace{}
+		ns.GenerateName = "e2e-"
+		Expect(client.Create(ctx, ns)).To(Succeed())
+		namespaces = append(namespaces, ns.Name)
+
+		gateway := tlsGateway(ns.Name, map[string]interface{}{
+			"name":      secret.Name,
+			"namespace": secret.Namespace,
+		})
+		gateway.SetAnnotations(map[string]string{
+			v1alpha1.SecretReplicationFromNamespaceAnnotation: secret.Namespace,
+		})
+		Expect(client.Create(ctx, gateway)).To(Succeed())
+
+		_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: gateway.GetName(), Namespace: gateway.GetNamespace()}})
+		Expect(err).ToNot(HaveOccurred())
+
+		newSecret := &corev1.Secret{}
+		err = client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+})
+
+// tlsGateway creates a new gateway with one https listener that uses the given certificate reference.
+func tlsGateway(namespace string, certificateRef map[string]interface{}) *unstructured.Unstructured {
+	gateway := &unstructured.Unstructured{}
+	gateway.SetGroupVersionKind(schema.GroupVersionKind{Group: gatewayctrl.GatewayGroup, Version: "v1", Kind: "Gateway"})
+	gateway.SetGenerateName("e2e-")
+	gateway.SetNamespace(namespace)
+	gateway.Object["spec"] = map[string]interface{}{
+		"gatewayClassName": "test",
+		"listeners": []interface{}{
+			map[string]interface{}{
+				"name":     "https",
+				"port":     int64(443),
+				"protocol": "HTTPS",
+				"tls": map[string]interface{}{
+					"certificateRefs": []interface{}{certificateRef},
+				},
+			},
+		},
+	}
+	return gateway
+}
@@ -1,38 +1,129 @@
 package ingressctrl
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/go-logr/logr"
 	"github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
-	"k8s.io/apimachinery/pkg/runtime"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
+// ReferrerKind is the kind that marks replicas that have been created for ingresses.
+const ReferrerKind = "ingress"
+
 type IngressController struct {
-	log    logr.Logger
-	client ctrlclient.Client
-	scheme *runtime.Scheme
+	log        logr.Logger
+	client     ctrlclient.Client
+	newIngress func() ctrlclient.Object
 	*errors.ErrorReporter
 }
 
-func New(log logr.Logger, client ctrlclient.Client, eventRecorder record.EventRecorder) reconcile.Reconciler {
+// New creates a new ingress controller that reconciles ingresses of the given api version.
+// Supported versions are networking.k8s.io/v1 and networking.k8s.io/v1beta1.
+func New(log logr.Logger, client ctrlclient.Client, eventRecorder record.EventRecorder, version schema.GroupVersion) (reconcile.Reconciler, error) {
+	newIngress, err := ingressFactory(version)
+	if err != nil {
+		return nil, err
+	}
 	return &IngressController{
 		log:           log,
 		client:        client,
+		newIngress:    newIngress,
 		ErrorReporter: errors.NewErrorReporter(eventRecorder),
-	}
+	}, nil
 }
 
 // AddToMgr adds the secrets reconiler to the given manager
-func AddToMgr(log logr.Logger, mgr ctrl.Manager) error {
+func AddToMgr(log logr.Logger, mgr ctrl.Manager, opts controller.Options) error {
+	version, err := DetectIngressVersion(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("Using ingress api version %q", version.String()))
+
+	newIngress, err := ingressFactory(version)
+	if err != nil {
+		return err
+	}
 	c := &IngressController{
 		log:           log,
 		client:        mgr.GetClient(),
-		scheme:        mgr.GetScheme(),
+		newIngress:    newIngress,
 		ErrorReporter: errors.NewErrorReporter(mgr.GetEventRecorderFor("SecretReplicationIngressController")),
 	}
-	return ctrl.NewControllerManagedBy(mgr).For(&networkingv1.Ingress{}).Complete(c)
+	if err := IndexFields(context.Background(), mgr.GetFieldIndexer(), newIngress()); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(newIngress()).
+		Watches(&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(c.MapSecret)).
+		WithOptions(opts).
+		Complete(c)
+}
+
+// IndexFields indexes the given kind of ingresses by the source secrets they reference, see replicator.SourceSecretIndex.
+func IndexFields(ctx context.Context, indexer ctrlclient.FieldIndexer, ingress ctrlclient.Object) error {
+	if err := indexer.IndexField(ctx, ingress, replicator.SourceSecretIndex, sourceSecrets); err != nil {
+		return fmt.Errorf("unable to index ingresses by source secret: %w", err)
+	}
+	return nil
+}
+
+// DetectIngressVersion uses the discovery api to determine the ingress api version that is served by the cluster.
+// networking.k8s.io/v1 is preferred, networking.k8s.io/v1beta1 is used for clusters that do not serve v1 ingresses yet.
+func DetectIngressVersion(config *rest.Config) (schema.GroupVersion, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return schema.GroupVersion{}, fmt.Errorf("unable to create discovery client: %w", err)
+	}
+
+	for _, version := range []schema.GroupVersion{networkingv1.SchemeGroupVersion, networkingv1beta1.SchemeGroupVersion} {
+		resources, err := discoveryClient.ServerResourcesForGroupVersion(version.String())
+		if err != nil {
+			// the group version is not served by the cluster
+			continue
+		}
+		for _, resource := range resources.APIResources {
+			if resource.Name == "ingresses" {
+				return version, nil
+			}
+		}
+	}
+	return schema.GroupVersion{}, fmt.Errorf("the cluster serves neither %s nor %s ingresses", networkingv1.SchemeGroupVersion, networkingv1beta1.SchemeGroupVersion)
+}
+
+// NewIngress returns an empty ingress of the given api version.
+func NewIngress(version schema.GroupVersion) (ctrlclient.Object, error) {
+	newIngress, err := ingressFactory(version)
+	if err != nil {
+		return nil, err
+	}
+	return newIngress(), nil
+}
+
+// ingressFactory returns a function that creates empty ingresses of the given api version.
+func ingressFactory(version schema.GroupVersion) (func() ctrlclient.Object, error) {
+	switch version {
+	case networkingv1.SchemeGroupVersion:
+		return func() ctrlclient.Object { return &networkingv1.Ingress{} }, nil
+	case networkingv1beta1.SchemeGroupVersion:
+		return func() ctrlclient.Object { return &networkingv1beta1.Ingress{} }, nil
+	default:
+		return nil, fmt.Errorf("unsupported ingress api version %q", version.String())
+	}
 }
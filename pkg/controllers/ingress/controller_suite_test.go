@@ -1,12 +1,19 @@
 package ingressctrl_test
 
 import (
+	"context"
 	"testing"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	networkingv1 "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	ingressctrl "github.com/schrodit/secret-replication-controller/pkg/controllers/ingress"
 )
 
 func TestSuite(t *testing.T) {
@@ -17,6 +24,10 @@ func TestSuite(t *testing.T) {
 var (
 	testenv *envtest.Environment
 	client  ctrlclient.Client
+	// cachedClient reads from an informer cache with the field indexes of the controller
+	// like the client of a manager.
+	cachedClient ctrlclient.Client
+	stopCache    context.CancelFunc
 )
 
 var _ = BeforeSuite(func() {
@@ -27,8 +38,44 @@ var _ = BeforeSuite(func() {
 
 	client, err = ctrlclient.New(restConfig, ctrlclient.Options{})
 	Expect(err).ToNot(HaveOccurred())
+
+	informers, err := cache.New(restConfig, cache.Options{})
+	Expect(err).ToNot(HaveOccurred())
+	var ctx context.Context
+	ctx, stopCache = context.WithCancel(context.Background())
+	for _, version := range []schema.GroupVersion{networkingv1.SchemeGroupVersion, networkingv1beta1.SchemeGroupVersion} {
+		// informers of versions that are not served would never sync
+		if !servesIngressVersion(version) {
+			continue
+		}
+		ingress, err := ingressctrl.NewIngress(version)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ingressctrl.IndexFields(ctx, informers, ingress)).To(Succeed())
+	}
+	go func() {
+		defer GinkgoRecover()
+		Expect(informers.Start(ctx)).To(Succeed())
+	}()
+	Expect(informers.WaitForCacheSync(ctx)).To(BeTrue())
+	cachedClient, err = ctrlclient.NewDelegatingClient(ctrlclient.NewDelegatingClientInput{
+		CacheReader: informers,
+		Client:      client,
+	})
+	Expect(err).ToNot(HaveOccurred())
 })
 
 var _ = AfterSuite(func() {
+	stopCache()
 	Expect(testenv.Stop()).To(Succeed())
 })
+
+// expectCached waits until the cached client has observed the given version of the object.
+func expectCached(obj ctrlclient.Object) {
+	cached := obj.DeepCopyObject().(ctrlclient.Object)
+	Eventually(func() string {
+		if err := cachedClient.Get(context.Background(), ctrlclient.ObjectKeyFromObject(obj), cached); err != nil {
+			return ""
+		}
+		return cached.GetResourceVersion()
+	}).Should(Equal(obj.GetResourceVersion()))
+}
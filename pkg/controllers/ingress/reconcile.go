@@ -5,7 +5,10 @@ import (
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -19,18 +22,26 @@ import (
 
 func (c *IngressController) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
 	ctx = logr.NewContext(ctx, c.log.WithValues("name", req.Name, "namespace", req.Namespace))
-	ingress := &networkingv1beta1.Ingress{}
+	ingress := c.newIngress()
 	if err := c.client.Get(ctx, req.NamespacedName, ingress); err != nil {
-		return reconcile.Result{}, err
+		if !apierrors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		// the ingress has been deleted so its replicas may not be needed anymore
+		return reconcile.Result{}, c.deleteUnreferencedReplicas(ctx, req.Namespace)
 	}
 
 	if err := c.reconcile(ctx, ingress); err != nil {
 		return reconcile.Result{}, err
 	}
+	// the tls secrets of the ingress may have changed
+	if err := c.deleteUnreferencedReplicas(ctx, req.Namespace); err != nil {
+		return reconcile.Result{}, err
+	}
 	return reconcile.Result{}, nil
 }
 
-func (c *IngressController) reconcile(ctx context.Context, ingress *networkingv1beta1.Ingress) error {
+func (c *IngressController) reconcile(ctx context.Context, ingress client.Object) error {
 	log := logr.FromContextOrDiscard(ctx)
 	log.V(10).Info("check replication for ingress")
 	srcNamespace, ok := helper.GetAnnotation(ingress, v1alpha1.SecretReplicationFromNamespaceAnnotations)
@@ -40,7 +51,7 @@ func (c *IngressController) reconcile(ctx context.Context, ingress *networkingv1
 	}
 
 	// get all secrets from the ingress
-	usedSecrets := getSecretsFromIngress(ingress)
+	usedSecrets := SecretsFromIngress(ingress)
 	if len(usedSecrets) == 0 {
 		log.V(10).Info("no secrets used by ingress")
 		return nil
@@ -50,31 +61,131 @@ func (c *IngressController) reconcile(ctx context.Context, ingress *networkingv1
 	if err := c.client.Get(ctx, client.ObjectKey{Name: srcNamespace}, &corev1.Namespace{}); err != nil {
 		return c.Report(ctx, fmt.Errorf("namespace %q not found", srcNamespace))
 	}
-	targetNamespace := ingress.Namespace
-
 	allErrs := interrors.ErrorList{}
-	for _, secretName := range usedSecrets {
+	results := replicator.ReplicateReferencedSecrets(ctx, c.client, ingress, srcNamespace, usedSecrets)
+	for i, res := range results {
+		if res.Err != nil {
+			allErrs = append(allErrs, res.Err)
+			continue
+		}
+		if res.Changed() {
+			c.Event(ingress, string(res.Action), fmt.Sprintf("%s replica of secret %s/%s", res.Action, srcNamespace, usedSecrets[i]))
+		}
+	}
 
-		// only sync secrets that exist in the given namespace
-		secret := &corev1.Secret{}
-		if err := c.client.Get(ctx, client.ObjectKey{Name: secretName, Namespace: srcNamespace}, secret); err != nil {
-			allErrs = append(allErrs, fmt.Errorf("unable to find secret %q: %w", secretName, err))
+	replicator.RecordResults("ingress", &corev1.Secret{}, results)
+
+	return c.Report(ctx, allErrs)
+}
+
+// deleteUnreferencedReplicas releases all secrets in the namespace that have been replicated for ingresses
+// but are not referenced by any ingress of the namespace anymore.
+// Replicas that are still referenced by other kinds are kept, see replicator.ReleaseReplica.
+func (c *IngressController) deleteUnreferencedReplicas(ctx context.Context, namespace string) error {
+	referenced, err := referencedSources(ctx, c.client, c.newIngress(), namespace)
+	if err != nil {
+		return err
+	}
+	if err := replicator.ReleaseUnreferencedReplicas(ctx, c.client, ReferrerKind, namespace, referenced); err != nil {
+		return c.Report(ctx, err)
+	}
+	return nil
+}
+
+// UnreferencedReplicas returns all secrets in the namespace that have been replicated for ingresses
+// but are not referenced by any ingress of the namespace anymore.
+// The ingresses are listed in the api version of the given ingress.
+func UnreferencedReplicas(ctx context.Context, kubeClient client.Client, kind client.Object, namespace string) ([]client.Object, error) {
+	referenced, err := referencedSources(ctx, kubeClient, kind, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return replicator.UnreferencedReplicas(ctx, kubeClient, ReferrerKind, namespace, referenced)
+}
+
+// referencedSources returns the keys of all source secrets that are referenced by the ingresses of the namespace.
+func referencedSources(ctx context.Context, kubeClient client.Client, kind client.Object, namespace string) (sets.String, error) {
+	ingresses, err := ListIngresses(ctx, kubeClient, kind, client.InNamespace(namespace))
+	if err != nil {
+		return nil, fmt.Errorf("unable to list ingresses in namespace %q: %w", namespace, err)
+	}
+	referenced := sets.NewString()
+	for _, ingress := range ingresses {
+		if !ingress.GetDeletionTimestamp().IsZero() {
 			continue
 		}
+		referenced.Insert(sourceSecrets(ingress)...)
+	}
+	return referenced, nil
+}
 
-		if err := replicator.New(c.client, secret).ReplicateTo(ctx, targetNamespace); err != nil {
-			allErrs = append(allErrs, err)
+// ListIngresses lists all ingresses of the api version of the given ingress.
+func ListIngresses(ctx context.Context, kubeClient client.Client, kind client.Object, opts ...client.ListOption) ([]client.Object, error) {
+	objects := make([]client.Object, 0)
+	switch kind.(type) {
+	case *networkingv1.Ingress:
+		list := &networkingv1.IngressList{}
+		if err := kubeClient.List(ctx, list, opts...); err != nil {
+			return nil, err
 		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case *networkingv1beta1.Ingress:
+		list := &networkingv1beta1.IngressList{}
+		if err := kubeClient.List(ctx, list, opts...); err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	default:
+		return nil, fmt.Errorf("unsupported ingress type %T", kind)
 	}
+	return objects, nil
+}
 
-	return c.Report(ctx, allErrs)
+// MapSecret maps a secret to all ingresses that reference the secret as source of a tls secret.
+// Replicas are mapped to the ingresses that reference their source so that deleted or changed replicas are restored.
+func (c *IngressController) MapSecret(obj client.Object) []reconcile.Request {
+	requests := make([]reconcile.Request, 0)
+	for _, key := range replicator.SourceKeys(obj) {
+		ingresses, err := ListIngresses(context.Background(), c.client, c.newIngress(), client.MatchingFields{replicator.SourceSecretIndex: key})
+		if err != nil {
+			c.log.Error(err, "unable to list ingresses for secret", "secret", key)
+			return nil
+		}
+		for _, ingress := range ingresses {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: ingress.GetName(), Namespace: ingress.GetNamespace()},
+			})
+		}
+	}
+	return requests
 }
 
-// getSecretsFromIngress returns all used secrets for the ingress.
-func getSecretsFromIngress(ingress *networkingv1beta1.Ingress) []string {
+// sourceSecrets returns the keys of all source secrets that are referenced by the ingress
+// in the format <namespace>/<name>.
+// It is also used as index function of the replicator.SourceSecretIndex.
+func sourceSecrets(ingress client.Object) []string {
+	return replicator.ReferencedSources(ingress, SecretsFromIngress(ingress))
+}
+
+// SecretsFromIngress returns all used secrets for the ingress.
+// The ingress can either be a networking.k8s.io/v1 or a networking.k8s.io/v1beta1 ingress.
+func SecretsFromIngress(ingress client.Object) []string {
 	secrets := sets.NewString()
-	for _, tls := range ingress.Spec.TLS {
-		secrets.Insert(tls.SecretName)
+	switch i := ingress.(type) {
+	case *networkingv1.Ingress:
+		for _, tls := range i.Spec.TLS {
+			secrets.Insert(tls.SecretName)
+		}
+	case *networkingv1beta1.Ingress:
+		for _, tls := range i.Spec.TLS {
+			secrets.Insert(tls.SecretName)
+		}
 	}
+	// ingresses without a tls secret name use the default certificate of the ingress controller
+	secrets.Delete("")
 	return secrets.List()
 }
@@ -2,177 +2,392 @@ package ingressctrl_test
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	interrors "github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
 	ingressctrl "github.com/schrodit/secret-replication-controller/pkg/controllers/ingress"
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/tools/record"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 var _ = Describe("controller", func() {
 
-	var (
-		ctrl       reconcile.Reconciler
-		secret     *corev1.Secret
-		namespaces []string
-	)
-
-	BeforeEach(func() {
-		secret = &corev1.Secret{}
-		secret.GenerateName = "e2e-"
-		secret.Namespace = "default"
-		secret.Data = map[string][]byte{
-			"key": []byte("value"),
-		}
-
-		Expect(client.Create(context.TODO(), secret)).To(Succeed())
-		namespaces = make([]string, 0)
-
-		ctrl = ingressctrl.New(logr.Discard(), client, record.NewFakeRecorder(1024))
-	})
-
-	AfterEach(func() {
-		ctx := context.Background()
-		defer ctx.Done()
-		Expect(client.Delete(ctx, secret)).To(Succeed())
-
-		for _, ns := range namespaces {
-			namespace := &corev1.Namespace{}
-			namespace.Name = ns
-			Expect(client.Delete(ctx, namespace)).To(Succeed())
-		}
-	})
-
-	It("should create a replicated secret in one namespace", func() {
-		ctx := context.Background()
-		defer ctx.Done()
-
-		ns := &corev1.Namespace{}
-		ns.GenerateName = "e2e-"
-		Expect(client.Create(ctx, ns))
-		namespaces = append(namespaces, ns.Name)
-
-		ingress := defaultIngress()
-		ingress.GenerateName = "e2e-"
-		ingress.Namespace = ns.Name
-		ingress.Annotations = map[string]string{
-			v1alpha1.SecretReplicationFromNamespaceAnnotation: secret.Namespace,
-		}
-		ingress.Spec.TLS = []networkingv1beta1.IngressTLS{
-			{
-				Hosts:      []string{"example.com"},
-				SecretName: secret.Name,
-			},
-		}
-
-		Expect(client.Create(ctx, ingress)).To(Succeed())
-
-		_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace}})
-		Expect(err).ToNot(HaveOccurred())
-
-		newSecret := &corev1.Secret{}
-		Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
-
-		Expect(newSecret.Data).To(Equal(secret.Data))
-		Expect(newSecret.Annotations).To(HaveKey(v1alpha1.SecretReplicationLastObservedHashAnnotation))
-		Expect(newSecret.Annotations[v1alpha1.SecretReplicationLastObservedHashAnnotation]).ToNot(Equal(""))
-	})
-
-	It("should create a replicated secret in one namespace with a custom prefix", func() {
-		ctx := context.Background()
-		defer ctx.Done()
-
-		customPrefix := "some-prefix"
-		v1alpha1.SecretReplicationFromNamespaceAnnotations.Add(customPrefix)
-		defer func() {
-			v1alpha1.SecretReplicationFromNamespaceAnnotations.Reset()
-		}()
-
-		ns := &corev1.Namespace{}
-		ns.GenerateName = "e2e-"
-		Expect(client.Create(ctx, ns))
-		namespaces = append(namespaces, ns.Name)
-
-		ingress := defaultIngress()
-		ingress.GenerateName = "e2e-"
-		ingress.Namespace = ns.Name
-		ingress.Annotations = map[string]string{
-			"some-prefix/from-namespace": secret.Namespace,
-		}
-		ingress.Spec.TLS = []networkingv1beta1.IngressTLS{
-			{
-				Hosts:      []string{"example.com"},
-				SecretName: secret.Name,
-			},
-		}
-
-		Expect(client.Create(ctx, ingress)).To(Succeed())
-
-		_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace}})
-		Expect(err).ToNot(HaveOccurred())
-
-		newSecret := &corev1.Secret{}
-		Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
-
-		Expect(newSecret.Data).To(Equal(secret.Data))
-		Expect(newSecret.Annotations).To(HaveKey(v1alpha1.SecretReplicationLastObservedHashAnnotation))
-		Expect(newSecret.Annotations[v1alpha1.SecretReplicationLastObservedHashAnnotation]).ToNot(Equal(""))
-	})
-
-	It("should update an existing secret when data of the source is updated", func() {
-		ctx := context.Background()
-		defer ctx.Done()
+	for _, version := range []schema.GroupVersion{networkingv1.SchemeGroupVersion, networkingv1beta1.SchemeGroupVersion} {
+		version := version
+		Context(version.String(), func() {
+
+			var (
+				ctrl       reconcile.Reconciler
+				recorder   *record.FakeRecorder
+				secret     *corev1.Secret
+				namespaces []string
+			)
+
+			BeforeEach(func() {
+				if !servesIngressVersion(version) {
+					Skip(fmt.Sprintf("ingress version %s is not served by the test environment", version))
+				}
+
+				secret = &corev1.Secret{}
+				secret.GenerateName = "e2e-"
+				secret.Namespace = "default"
+				secret.Annotations = map[string]string{
+					v1alpha1.SecretReplicationAllowedNamespacesAnnotation: "e2e-*",
+				}
+				secret.Data = map[string][]byte{
+					"key": []byte("value"),
+				}
+
+				Expect(client.Create(context.TODO(), secret)).To(Succeed())
+				namespaces = make([]string, 0)
+
+				recorder = record.NewFakeRecorder(1024)
+				var err error
+				ctrl, err = ingressctrl.New(logr.Discard(), client, recorder, version)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				if secret == nil {
+					// the version is not served by the test environment
+					return
+				}
+				ctx := context.Background()
+				defer ctx.Done()
+				Expect(client.Delete(ctx, secret)).To(Succeed())
+
+				for _, ns := range namespaces {
+					namespace := &corev1.Namespace{}
+					namespace.Name = ns
+					Expect(client.Delete(ctx, namespace)).To(Succeed())
+				}
+			})
+
+			It("should create a replicated secret in one namespace", func() {
+				ctx := context.Background()
+				defer ctx.Done()
+
+				ns := &corev1.Namespace{}
+				ns.GenerateName = "e2e-"
+				Expect(client.Create(ctx, ns))
+				namespaces = append(namespaces, ns.Name)
+
+				ingress := tlsIngress(version, ns.Name, map[string]string{
+					v1alpha1.SecretReplicationFromNamespaceAnnotation: secret.Namespace,
+				}, secret.Name)
+
+				Expect(client.Create(ctx, ingress)).To(Succeed())
+
+				_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: ingress.GetName(), Namespace: ingress.GetNamespace()}})
+				Expect(err).ToNot(HaveOccurred())
+
+				newSecret := &corev1.Secret{}
+				Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
+
+				Expect(newSecret.Data).To(Equal(secret.Data))
+				Expect(newSecret.Annotations).To(HaveKey(v1alpha1.SecretReplicationLastObservedHashAnnotation))
+				Expect(newSecret.Annotations[v1alpha1.SecretReplicationLastObservedHashAnnotation]).ToNot(Equal(""))
+			})
+
+			It("should create a replicated secret in one namespace with a custom prefix", func() {
+				ctx := context.Background()
+				defer ctx.Done()
+
+				customPrefix := "some-prefix"
+				v1alpha1.SecretReplicationFromNamespaceAnnotations.Add(customPrefix)
+				defer func() {
+					v1alpha1.SecretReplicationFromNamespaceAnnotations.Reset()
+				}()
+
+				ns := &corev1.Namespace{}
+				ns.GenerateName = "e2e-"
+				Expect(client.Create(ctx, ns))
+				namespaces = append(namespaces, ns.Name)
+
+				ingress := tlsIngress(version, ns.Name, map[string]string{
+					"some-prefix/from-namespace": secret.Namespace,
+				}, secret.Name)
+
+				Expect(client.Create(ctx, ingress)).To(Succeed())
+
+				_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: ingress.GetName(), Namespace: ingress.GetNamespace()}})
+				Expect(err).ToNot(HaveOccurred())
+
+				newSecret := &corev1.Secret{}
+				Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
+
+				Expect(newSecret.Data).To(Equal(secret.Data))
+				Expect(newSecret.Annotations).To(HaveKey(v1alpha1.SecretReplicationLastObservedHashAnnotation))
+				Expect(newSecret.Annotations[v1alpha1.SecretReplicationLastObservedHashAnnotation]).ToNot(Equal(""))
+			})
+
+			It("should update an existing secret when data of the source is updated", func() {
+				ctx := context.Background()
+				defer ctx.Done()
+
+				ns := &corev1.Namespace{}
+				ns.GenerateName = "e2e-"
+				Expect(client.Create(ctx, ns))
+				namespaces = append(namespaces, ns.Name)
+
+				ingress := tlsIngress(version, ns.Name, map[string]string{
+					v1alpha1.SecretReplicationFromNamespaceAnnotation: secret.Namespace,
+				}, secret.Name)
+
+				Expect(client.Create(ctx, ingress)).To(Succeed())
+
+				_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: ingress.GetName(), Namespace: ingress.GetNamespace()}})
+				Expect(err).ToNot(HaveOccurred())
+
+				newSecret := &corev1.Secret{}
+				Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
+
+				secret.Data = map[string][]byte{
+					"other": []byte("test"),
+				}
+				Expect(client.Update(ctx, secret)).To(Succeed())
+
+				_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: ingress.GetName(), Namespace: ingress.GetNamespace()}})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
+				Expect(newSecret.Data).To(Equal(secret.Data))
+			})
+
+			It("should delete a replicated secret when it is not referenced by an ingress anymore", func() {
+				ctx := context.Background()
+				defer ctx.Done()
+
+				ns := &corev1.Namespace{}
+				ns.GenerateName = "e2e-"
+				Expect(client.Create(ctx, ns))
+				namespaces = append(namespaces, ns.Name)
+
+				ingress := tlsIngress(version, ns.Name, map[string]string{
+					v1alpha1.SecretReplicationFromNamespaceAnnotation: secret.Namespace,
+				}, secret.Name)
+				Expect(client.Create(ctx, ingress)).To(Succeed())
+
+				_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: ingress.GetName(), Namespace: ingress.GetNamespace()}})
+				Expect(err).ToNot(HaveOccurred())
+
+				newSecret := &corev1.Secret{}
+				Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
+				Expect(replicator.Referrers(newSecret).List()).To(ConsistOf("ingress"))
+
+				By("create an unrelated secret in the namespace of the ingress")
+				unrelated := &corev1.Secret{}
+				unrelated.Name = "unrelated"
+				unrelated.Namespace = ns.Name
+				Expect(client.Create(ctx, unrelated)).To(Succeed())
+
+				Expect(client.Delete(ctx, ingress)).To(Succeed())
+				_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: ingress.GetName(), Namespace: ingress.GetNamespace()}})
+				Expect(err).ToNot(HaveOccurred())
+
+				err = client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				Expect(client.Get(ctx, types.NamespacedName{Name: unrelated.Name, Namespace: ns.Name}, unrelated)).To(Succeed())
+			})
+
+			It("should keep a replica that is also referenced by another kind when the ingress is deleted", func() {
+				ctx := context.Background()
+				defer ctx.Done()
+
+				ns := &corev1.Namespace{}
+				ns.GenerateName = "e2e-"
+				Expect(client.Create(ctx, ns))
+				namespaces = append(namespaces, ns.Name)
+
+				ingress := tlsIngress(version, ns.Name, map[string]string{
+					v1alpha1.SecretReplicationFromNamespaceAnnotation: secret.Namespace,
+				}, secret.Name)
+				Expect(client.Create(ctx, ingress)).To(Succeed())
+
+				_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: ingress.GetName(), Namespace: ingress.GetNamespace()}})
+				Expect(err).ToNot(HaveOccurred())
+
+				By("reference the replica by another kind")
+				newSecret := &corev1.Secret{}
+				Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
+				patch := ctrlclient.MergeFrom(newSecret.DeepCopy())
+				newSecret.Annotations[v1alpha1.SecretReplicationReferencedByAnnotationPrefix+"serviceaccount"] = "true"
+				Expect(client.Patch(ctx, newSecret, patch)).To(Succeed())
+
+				Expect(client.Delete(ctx, ingress)).To(Succeed())
+				_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: ingress.GetName(), Namespace: ingress.GetNamespace()}})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
+				Expect(replicator.Referrers(newSecret).List()).To(ConsistOf("serviceaccount"))
+			})
+
+			It("should map source secrets and their replicas to the ingresses that reference them", func() {
+				ctx := context.Background()
+				defer ctx.Done()
+
+				ns := &corev1.Namespace{}
+				ns.GenerateName = "e2e-"
+				Expect(client.Create(ctx, ns))
+				namespaces = append(namespaces, ns.Name)
+
+				ingress := tlsIngress(version, ns.Name, map[string]string{
+					v1alpha1.SecretReplicationFromNamespaceAnnotation: secret.Namespace,
+				}, secret.Name)
+				Expect(client.Create(ctx, ingress)).To(Succeed())
+				expectCached(ingress)
+
+				_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: ingress.GetName(), Namespace: ingress.GetNamespace()}})
+				Expect(err).ToNot(HaveOccurred())
+				newSecret := &corev1.Secret{}
+				Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
+
+				mapCtrl, err := ingressctrl.New(logr.Discard(), cachedClient, recorder, version)
+				Expect(err).ToNot(HaveOccurred())
+				expected := reconcile.Request{NamespacedName: types.NamespacedName{Name: ingress.GetName(), Namespace: ingress.GetNamespace()}}
+				Expect(mapCtrl.(*ingressctrl.IngressController).MapSecret(secret)).To(ConsistOf(expected))
+				Expect(mapCtrl.(*ingressctrl.IngressController).MapSecret(newSecret)).To(ConsistOf(expected))
+
+				unrelated := &corev1.Secret{}
+				unrelated.Name = "unrelated"
+				unrelated.Namespace = ns.Name
+				Expect(mapCtrl.(*ingressctrl.IngressController).MapSecret(unrelated)).To(BeEmpty())
+			})
+
+			It("should not replicate a secret that does not allow the namespace of the ingress", func() {
+				ctx := context.Background()
+				defer ctx.Done()
+
+				secret.Annotations = nil
+				Expect(client.Update(ctx, secret)).To(Succeed())
+
+				ns := &corev1.Namespace{}
+				ns.GenerateName = "e2e-"
+				Expect(client.Create(ctx, ns))
+				namespaces = append(namespaces, ns.Name)
+
+				ingress := tlsIngress(version, ns.Name, map[string]string{
+					v1alpha1.SecretReplicationFromNamespaceAnnotation: secret.Namespace,
+				}, secret.Name)
+
+				Expect(client.Create(ctx, ingress)).To(Succeed())
+
+				_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: ingress.GetName(), Namespace: ingress.GetNamespace()}})
+				Expect(err).ToNot(HaveOccurred())
+
+				newSecret := &corev1.Secret{}
+				err = client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				Expect(recorder.Events).To(Receive(HavePrefix(fmt.Sprintf("%s %s", corev1.EventTypeWarning, interrors.Forbidden))))
+			})
+
+			It("should replicate a secret if the namespace of the source allows the namespace of the ingress", func() {
+				ctx := context.Background()
+				defer ctx.Done()
+
+				secret.Annotations = nil
+				Expect(client.Update(ctx, secret)).To(Succeed())
+
+				srcNs := &corev1.Namespace{}
+				srcNs.GenerateName = "e2e-"
+				srcNs.Annotations = map[string]string{
+					v1alpha1.SecretReplicationAllowedNamespaceSelectorAnnotation: "team=a",
+				}
+				Expect(client.Create(ctx, srcNs)).To(Succeed())
+				namespaces = append(namespaces, srcNs.Name)
+
+				src := &corev1.Secret{}
+				src.Name = "tls"
+				src.Namespace = srcNs.Name
+				src.Data = secret.Data
+				Expect(client.Create(ctx, src)).To(Succeed())
+
+				ns := &corev1.Namespace{}
+				ns.GenerateName = "e2e-"
+				ns.Labels = map[string]string{
+					"team": "a",
+				}
+				Expect(client.Create(ctx, ns))
+				namespaces = append(namespaces, ns.Name)
+
+				ingress := tlsIngress(version, ns.Name, map[string]string{
+					v1alpha1.SecretReplicationFromNamespaceAnnotation: srcNs.Name,
+				}, src.Name)
+
+				Expect(client.Create(ctx, ingress)).To(Succeed())
+
+				_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: ingress.GetName(), Namespace: ingress.GetNamespace()}})
+				Expect(err).ToNot(HaveOccurred())
+
+				newSecret := &corev1.Secret{}
+				Expect(client.Get(ctx, types.NamespacedName{Name: src.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
+				Expect(newSecret.Data).To(Equal(src.Data))
+			})
+		})
+	}
 
-		ns := &corev1.Namespace{}
-		ns.GenerateName = "e2e-"
-		Expect(client.Create(ctx, ns))
-		namespaces = append(namespaces, ns.Name)
+})
 
-		ingress := defaultIngress()
+// tlsIngress creates a new ingress of the given api version that uses the given tls secret.
+func tlsIngress(version schema.GroupVersion, namespace string, annotations map[string]string, secretName string) ctrlclient.Object {
+	if version == networkingv1beta1.SchemeGroupVersion {
+		ingress := &networkingv1beta1.Ingress{}
 		ingress.GenerateName = "e2e-"
-		ingress.Namespace = ns.Name
-		ingress.Annotations = map[string]string{
-			v1alpha1.SecretReplicationFromNamespaceAnnotation: secret.Namespace,
+		ingress.Namespace = namespace
+		ingress.Annotations = annotations
+		ingress.Spec.Rules = []networkingv1beta1.IngressRule{
+			{},
 		}
 		ingress.Spec.TLS = []networkingv1beta1.IngressTLS{
 			{
 				Hosts:      []string{"example.com"},
-				SecretName: secret.Name,
+				SecretName: secretName,
 			},
 		}
+		return ingress
+	}
 
-		Expect(client.Create(ctx, ingress)).To(Succeed())
-
-		_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace}})
-		Expect(err).ToNot(HaveOccurred())
-
-		newSecret := &corev1.Secret{}
-		Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
-
-		secret.Data = map[string][]byte{
-			"other": []byte("test"),
-		}
-		Expect(client.Update(ctx, secret)).To(Succeed())
-
-		_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace}})
-		Expect(err).ToNot(HaveOccurred())
-
-		Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
-		Expect(newSecret.Data).To(Equal(secret.Data))
-	})
-
-})
-
-func defaultIngress() *networkingv1beta1.Ingress {
-	ingress := &networkingv1beta1.Ingress{}
-	ingress.Spec.Rules = []networkingv1beta1.IngressRule{
+	ingress := &networkingv1.Ingress{}
+	ingress.GenerateName = "e2e-"
+	ingress.Namespace = namespace
+	ingress.Annotations = annotations
+	ingress.Spec.Rules = []networkingv1.IngressRule{
 		{},
 	}
+	ingress.Spec.TLS = []networkingv1.IngressTLS{
+		{
+			Hosts:      []string{"example.com"},
+			SecretName: secretName,
+		},
+	}
 	return ingress
 }
+
+// servesIngressVersion checks whether the test environment serves ingresses of the given api version.
+func servesIngressVersion(version schema.GroupVersion) bool {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(testenv.Config)
+	Expect(err).ToNot(HaveOccurred())
+	resources, err := discoveryClient.ServerResourcesForGroupVersion(version.String())
+	if err != nil {
+		return false
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == "ingresses" {
+			return true
+		}
+	}
+	return false
+}
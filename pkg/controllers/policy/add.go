@@ -0,0 +1,49 @@
+package policyctrl
+
+import (
+	"github.com/go-logr/logr"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+	"github.com/schrodit/secret-replication-controller/pkg/controllers/predicates"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// controllerName is the name of the controller that is used in metrics.
+const controllerName = "policy"
+
+// referrerKind is the kind that marks replicas that have been created for replication policies.
+const referrerKind = "replicationpolicy"
+
+type policyController struct {
+	log    logr.Logger
+	client ctrlclient.Client
+	scheme *runtime.Scheme
+	*errors.ErrorReporter
+}
+
+// AddToMgr adds the replication policy reconciler to the given manager
+func AddToMgr(log logr.Logger, mgr manager.Manager, opts controller.Options) error {
+	c := &policyController{
+		log:           log,
+		client:        mgr.GetClient(),
+		scheme:        mgr.GetScheme(),
+		ErrorReporter: errors.NewErrorReporter(mgr.GetEventRecorderFor("SecretReplicationPolicyController")),
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.ReplicationPolicy{}).
+		WithOptions(opts).
+		Watches(&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(c.secretToPolicies)).
+		Watches(&source.Kind{Type: &corev1.Namespace{}},
+			handler.EnqueueRequestsFromMapFunc(c.namespaceToPolicies),
+			builder.WithPredicates(predicates.NamespaceCreatedOrLabelsChanged())).
+		Complete(c)
+}
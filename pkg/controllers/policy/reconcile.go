@@ -0,0 +1,248 @@
+package policyctrl
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/go-logr/logr"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+)
+
+func (c *policyController) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	ctx = logr.NewContext(ctx, c.log.WithValues("name", req.Name, "namespace", req.Namespace))
+	policy := &v1alpha1.ReplicationPolicy{}
+	if err := c.client.Get(ctx, req.NamespacedName, policy); err != nil {
+		return reconcile.Result{}, ctrlclient.IgnoreNotFound(err)
+	}
+
+	if !policy.DeletionTimestamp.IsZero() {
+		if err := c.delete(ctx, policy); err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{}, nil
+	}
+
+	if err := c.reconcile(ctx, policy); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+func (c *policyController) reconcile(ctx context.Context, policy *v1alpha1.ReplicationPolicy) error {
+	log := logr.FromContextOrDiscard(ctx)
+	log.V(10).Info("check replication for policy")
+
+	if !controllerutil.ContainsFinalizer(policy, v1alpha1.SecretReplicationFinalizer) {
+		controllerutil.AddFinalizer(policy, v1alpha1.SecretReplicationFinalizer)
+		if err := c.client.Update(ctx, policy); err != nil {
+			return fmt.Errorf("unable to add finalizer: %w", err)
+		}
+	}
+
+	targets, err := replicator.TargetsFromPolicy(policy)
+	if err != nil {
+		return c.reportFailure(ctx, policy, err)
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.client.Get(ctx, sourceKey(policy), secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		// the source secret is gone so its replicas are outdated
+		notFound := errors.Error{
+			Src:    policy,
+			Reason: errors.SourceNotFound,
+			Msg:    fmt.Sprintf("secret %q not found", policy.Spec.SecretName),
+		}
+		allErrs := errors.ErrorList{notFound}
+		if err := c.deleteReplicasExcept(ctx, policy, sourceStub(policy), sets.NewString()); err != nil {
+			allErrs = append(allErrs, err)
+		}
+		replicator.RecordManagedReplicas(controllerName, sourceStub(policy), policy, nil)
+		if err := c.updateStatus(ctx, policy, nil, notFound); err != nil {
+			allErrs = append(allErrs, err)
+		}
+		return c.Report(ctx, allErrs)
+	}
+
+	namespaces, err := targets.Resolve(ctx, c.client, policy)
+	if err != nil {
+		return c.reportFailure(ctx, policy, err)
+	}
+
+	var (
+		r       = replicator.New(c.client, secret).ReferencedBy(referrerKind)
+		results = r.ReplicateToAll(ctx, namespaces.List())
+		allErrs = errors.ErrorList{}
+	)
+	for _, res := range results {
+		if res.Err != nil {
+			allErrs = append(allErrs, res.Err)
+			continue
+		}
+		if res.Changed() {
+			c.Event(policy, string(res.Action), res.Message())
+		}
+	}
+	replicator.RecordResults(controllerName, secret, results)
+	replicator.RecordManagedReplicas(controllerName, secret, policy, results)
+
+	// remove replicas from namespaces that are not targeted anymore
+	if err := c.deleteReplicasExcept(ctx, policy, secret, namespaces); err != nil {
+		allErrs = append(allErrs, err)
+	}
+
+	if err := c.updateStatus(ctx, policy, replicator.TargetStatuses(secret, policy.Status.Targets, results), nil); err != nil {
+		allErrs = append(allErrs, err)
+	}
+
+	return c.Report(ctx, allErrs)
+}
+
+// updateStatus updates the status of the policy with the given target statuses and the failure of the policy if it has changed.
+func (c *policyController) updateStatus(ctx context.Context, policy *v1alpha1.ReplicationPolicy, targets []v1alpha1.ReplicationTargetStatus, failure error) error {
+	status := v1alpha1.ReplicationPolicyStatus{
+		ObservedGeneration: policy.Generation,
+		Targets:            targets,
+	}
+	if failure != nil {
+		status.Reason = string(errors.ReasonOf(failure))
+		status.Message = failure.Error()
+	}
+	if apiequality.Semantic.DeepEqual(policy.Status, status) {
+		return nil
+	}
+
+	policy.Status = status
+	if err := c.client.Status().Update(ctx, policy); err != nil {
+		return fmt.Errorf("unable to update status: %w", err)
+	}
+	return nil
+}
+
+// reportFailure writes the failure to the status of the policy and reports it.
+// The target statuses are kept as the replicas are not changed.
+func (c *policyController) reportFailure(ctx context.Context, policy *v1alpha1.ReplicationPolicy, failure error) error {
+	allErrs := errors.ErrorList{failure}
+	if err := c.updateStatus(ctx, policy, policy.Status.Targets, failure); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	return c.Report(ctx, allErrs)
+}
+
+// delete removes all replicas of the policy's secret and removes the finalizer.
+func (c *policyController) delete(ctx context.Context, policy *v1alpha1.ReplicationPolicy) error {
+	if !controllerutil.ContainsFinalizer(policy, v1alpha1.SecretReplicationFinalizer) {
+		return nil
+	}
+
+	if err := c.deleteReplicasExcept(ctx, policy, sourceStub(policy), sets.NewString()); err != nil {
+		return c.Report(ctx, err)
+	}
+	replicator.ForgetManagedReplicas(controllerName, sourceStub(policy), policy)
+
+	controllerutil.RemoveFinalizer(policy, v1alpha1.SecretReplicationFinalizer)
+	if err := c.client.Update(ctx, policy); err != nil {
+		return fmt.Errorf("unable to remove finalizer: %w", err)
+	}
+	return nil
+}
+
+// deleteReplicasExcept deletes all replicas of the secret that are neither in one of the given namespaces
+// nor targeted by another policy of the same secret.
+func (c *policyController) deleteReplicasExcept(ctx context.Context, policy *v1alpha1.ReplicationPolicy, secret *corev1.Secret, namespaces sets.String) error {
+	policyList := &v1alpha1.ReplicationPolicyList{}
+	if err := c.client.List(ctx, policyList, ctrlclient.InNamespace(policy.Namespace)); err != nil {
+		return fmt.Errorf("unable to list replication policies: %w", err)
+	}
+
+	for i := range policyList.Items {
+		other := &policyList.Items[i]
+		if other.Name == policy.Name || other.Spec.SecretName != policy.Spec.SecretName || !other.DeletionTimestamp.IsZero() {
+			continue
+		}
+		targets, err := replicator.TargetsFromPolicy(other)
+		if err != nil {
+			return err
+		}
+		otherNamespaces, err := targets.Resolve(ctx, c.client, other)
+		if err != nil {
+			return err
+		}
+		namespaces = namespaces.Union(otherNamespaces)
+	}
+	return replicator.New(c.client, secret).ReferencedBy(referrerKind).DeleteReplicasExcept(ctx, namespaces)
+}
+
+// secretToPolicies maps a secret to all policies that replicate the secret.
+// Replicas are mapped to the policies of their source so that changes of their data are reverted.
+func (c *policyController) secretToPolicies(obj ctrlclient.Object) []reconcile.Request {
+	srcKey := types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}
+	if key, ok := replicator.SourceOf(obj); ok {
+		srcKey = key
+	}
+
+	policyList := &v1alpha1.ReplicationPolicyList{}
+	if err := c.client.List(context.Background(), policyList, ctrlclient.InNamespace(srcKey.Namespace)); err != nil {
+		c.log.Error(err, "unable to list replication policies for secret", "name", srcKey.Name, "namespace", srcKey.Namespace)
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0)
+	for _, policy := range policyList.Items {
+		if policy.Spec.SecretName == srcKey.Name {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace},
+			})
+		}
+	}
+	return requests
+}
+
+// namespaceToPolicies maps a namespace to all policies that replicate their secret into that namespace.
+func (c *policyController) namespaceToPolicies(obj ctrlclient.Object) []reconcile.Request {
+	policyList := &v1alpha1.ReplicationPolicyList{}
+	if err := c.client.List(context.Background(), policyList); err != nil {
+		c.log.Error(err, "unable to list replication policies for namespace", "namespace", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0)
+	for i := range policyList.Items {
+		policy := &policyList.Items[i]
+		targets, err := replicator.TargetsFromPolicy(policy)
+		if err != nil || !targets.Matches(obj) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace},
+		})
+	}
+	return requests
+}
+
+// sourceKey returns the key of the source secret of the policy.
+func sourceKey(policy *v1alpha1.ReplicationPolicy) types.NamespacedName {
+	return types.NamespacedName{Name: policy.Spec.SecretName, Namespace: policy.Namespace}
+}
+
+// sourceStub returns a secret that only identifies the source secret of the policy.
+// It is used to find replicas when the actual source secret may not exist anymore.
+func sourceStub(policy *v1alpha1.ReplicationPolicy) *corev1.Secret {
+	secret := &corev1.Secret{}
+	secret.Name = policy.Spec.SecretName
+	secret.Namespace = policy.Namespace
+	return secret
+}
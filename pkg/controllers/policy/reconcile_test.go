@@ -0,0 +1,248 @@
+package policyctrl
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("controller", func() {
+
+	var (
+		ctrl       *policyController
+		secret     *corev1.Secret
+		policy     *v1alpha1.ReplicationPolicy
+		namespaces []string
+	)
+
+	BeforeEach(func() {
+		ctx := context.Background()
+		secret = &corev1.Secret{}
+		secret.GenerateName = "e2e-"
+		secret.Namespace = "default"
+		secret.Data = map[string][]byte{
+			"key": []byte("value"),
+		}
+		Expect(client.Create(ctx, secret)).To(Succeed())
+
+		policy = &v1alpha1.ReplicationPolicy{}
+		policy.GenerateName = "e2e-"
+		policy.Namespace = secret.Namespace
+		policy.Spec.SecretName = secret.Name
+		namespaces = make([]string, 0)
+
+		ctrl = &policyController{
+			log:           logr.Discard(),
+			client:        client,
+			ErrorReporter: errors.NewErrorReporter(record.NewFakeRecorder(1024)),
+		}
+	})
+
+	AfterEach(func() {
+		ctx := context.Background()
+		Expect(client.Delete(ctx, secret)).To(Succeed())
+
+		// remove the finalizer so that the policy is actually deleted
+		if err := client.Get(ctx, types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace}, policy); err == nil {
+			policy.Finalizers = nil
+			Expect(client.Update(ctx, policy)).To(Succeed())
+			Expect(ctrlclient.IgnoreNotFound(client.Delete(ctx, policy))).To(Succeed())
+		}
+
+		for _, ns := range namespaces {
+			namespace := &corev1.Namespace{}
+			namespace.Name = ns
+			Expect(client.Delete(ctx, namespace)).To(Succeed())
+		}
+	})
+
+	It("should replicate the secret to all namespaces matching the policy", func() {
+		ctx := context.Background()
+
+		By("create test namespaces")
+		ns1 := &corev1.Namespace{}
+		ns1.GenerateName = "e2e-"
+		Expect(client.Create(ctx, ns1))
+		namespaces = append(namespaces, ns1.Name)
+
+		ns2 := &corev1.Namespace{}
+		ns2.GenerateName = "e2e-"
+		ns2.Labels = map[string]string{"team": "payments"}
+		Expect(client.Create(ctx, ns2))
+		namespaces = append(namespaces, ns2.Name)
+
+		policy.Spec.Namespaces = []string{ns1.Name}
+		policy.Spec.NamespaceSelector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{"team": "payments"},
+		}
+		Expect(client.Create(ctx, policy)).To(Succeed())
+
+		_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		newSecret := &corev1.Secret{}
+		Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns1.Name}, newSecret)).To(Succeed())
+		Expect(newSecret.Data).To(Equal(secret.Data))
+		Expect(newSecret.Annotations).To(HaveKeyWithValue(v1alpha1.SecretReplicationReplicaOfAnnotation, "default/"+secret.Name))
+
+		Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns2.Name}, newSecret)).To(Succeed())
+		Expect(newSecret.Data).To(Equal(secret.Data))
+
+		Expect(client.Get(ctx, types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace}, policy)).To(Succeed())
+		Expect(policy.Status.ObservedGeneration).To(Equal(policy.Generation))
+		Expect(policy.Status.Targets).To(HaveLen(2))
+		for _, target := range policy.Status.Targets {
+			Expect(target.Namespace).To(BeElementOf(ns1.Name, ns2.Name))
+			Expect(target.LastSyncedHash).To(Equal(newSecret.Annotations[v1alpha1.SecretReplicationLastObservedHashAnnotation]))
+			Expect(target.LastSyncTime).ToNot(BeNil())
+		}
+	})
+
+	It("should write the failure to the status if the target namespaces cannot be resolved", func() {
+		ctx := context.Background()
+
+		policy.Spec.Namespaces = []string{"e2e-does-not-exist"}
+		Expect(client.Create(ctx, policy)).To(Succeed())
+
+		_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(client.Get(ctx, types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace}, policy)).To(Succeed())
+		Expect(policy.Status.ObservedGeneration).To(Equal(policy.Generation))
+		Expect(policy.Status.Reason).To(Equal(string(errors.InvalidNamespace)))
+		Expect(policy.Status.Message).ToNot(BeEmpty())
+
+		By("create the target namespace")
+		ns := &corev1.Namespace{}
+		ns.Name = "e2e-does-not-exist"
+		Expect(client.Create(ctx, ns)).To(Succeed())
+		namespaces = append(namespaces, ns.Name)
+
+		_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(client.Get(ctx, types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace}, policy)).To(Succeed())
+		Expect(policy.Status.Reason).To(BeEmpty())
+		Expect(policy.Status.Message).To(BeEmpty())
+		Expect(policy.Status.Targets).To(HaveLen(1))
+	})
+
+	It("should delete the replicated secrets when the policy is deleted", func() {
+		ctx := context.Background()
+
+		By("create test namespace")
+		ns := &corev1.Namespace{}
+		ns.GenerateName = "e2e-"
+		Expect(client.Create(ctx, ns))
+		namespaces = append(namespaces, ns.Name)
+
+		policy.Spec.Namespaces = []string{ns.Name}
+		Expect(client.Create(ctx, policy)).To(Succeed())
+
+		_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, &corev1.Secret{})).To(Succeed())
+
+		Expect(client.Delete(ctx, policy)).To(Succeed())
+		_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		err = client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, &corev1.Secret{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		err = client.Get(ctx, types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace}, &v1alpha1.ReplicationPolicy{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should not delete replicas that are also replicated by the annotations of the secret", func() {
+		ctx := context.Background()
+
+		By("create test namespace")
+		ns := &corev1.Namespace{}
+		ns.GenerateName = "e2e-"
+		Expect(client.Create(ctx, ns))
+		namespaces = append(namespaces, ns.Name)
+
+		_, err := replicator.New(client, secret).ReplicateTo(ctx, ns.Name)
+		Expect(err).ToNot(HaveOccurred())
+
+		policy.Spec.Namespaces = []string{ns.Name}
+		Expect(client.Create(ctx, policy)).To(Succeed())
+		_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(client.Delete(ctx, policy)).To(Succeed())
+		_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		replica := &corev1.Secret{}
+		Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, replica)).To(Succeed())
+		Expect(replicator.Referrers(replica).List()).To(ConsistOf(replicator.AnnotationReferrer))
+	})
+
+	It("should not delete replicas that are targeted by another policy of the same secret", func() {
+		ctx := context.Background()
+
+		By("create test namespace")
+		ns := &corev1.Namespace{}
+		ns.GenerateName = "e2e-"
+		Expect(client.Create(ctx, ns))
+		namespaces = append(namespaces, ns.Name)
+
+		other := &v1alpha1.ReplicationPolicy{}
+		other.GenerateName = "e2e-"
+		other.Namespace = secret.Namespace
+		other.Spec.SecretName = secret.Name
+		other.Spec.Namespaces = []string{ns.Name}
+		Expect(client.Create(ctx, other)).To(Succeed())
+		defer func() {
+			Expect(client.Delete(ctx, other)).To(Succeed())
+		}()
+
+		policy.Spec.Namespaces = []string{ns.Name}
+		Expect(client.Create(ctx, policy)).To(Succeed())
+		_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(client.Delete(ctx, policy)).To(Succeed())
+		_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, &corev1.Secret{})).To(Succeed())
+	})
+
+	It("should map a secret to the policies that replicate it", func() {
+		ctx := context.Background()
+
+		Expect(client.Create(ctx, policy)).To(Succeed())
+		Expect(ctrl.secretToPolicies(secret)).To(ConsistOf(reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace},
+		}))
+	})
+
+	It("should map a replica to the policies that replicate its source", func() {
+		ctx := context.Background()
+
+		Expect(client.Create(ctx, policy)).To(Succeed())
+		replica := &corev1.Secret{}
+		replica.Name = "replica"
+		replica.Namespace = "other"
+		replica.Annotations = map[string]string{
+			v1alpha1.SecretReplicationReplicaOfAnnotation: replicator.ReplicaOf(secret),
+		}
+		Expect(ctrl.secretToPolicies(replica)).To(ConsistOf(reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace},
+		}))
+	})
+})
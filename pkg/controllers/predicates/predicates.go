@@ -0,0 +1,72 @@
+package predicates
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1/helper"
+)
+
+// NamespaceCreatedOrLabelsChanged only lets create events and label changes of namespaces pass.
+func NamespaceCreatedOrLabelsChanged() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool { return true },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !labels.Equals(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels())
+		},
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+}
+
+// PodPullSecretsChanged only lets create and delete events of pods pass
+// as well as updates that change the image pull secrets, the service account or the from-namespace annotation of a pod.
+// Status updates of pods are filtered as they do not affect the replicated image pull secrets.
+func PodPullSecretsChanged() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool { return true },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPod, ok := e.ObjectOld.(*corev1.Pod)
+			if !ok {
+				return true
+			}
+			newPod, ok := e.ObjectNew.(*corev1.Pod)
+			if !ok {
+				return true
+			}
+			oldNamespace, _ := helper.GetAnnotation(oldPod, v1alpha1.SecretReplicationFromNamespaceAnnotations)
+			newNamespace, _ := helper.GetAnnotation(newPod, v1alpha1.SecretReplicationFromNamespaceAnnotations)
+			return oldNamespace != newNamespace || oldPod.Spec.ServiceAccountName != newPod.Spec.ServiceAccountName ||
+				!apiequality.Semantic.DeepEqual(oldPod.Spec.ImagePullSecrets, newPod.Spec.ImagePullSecrets)
+		},
+		DeleteFunc:  func(event.DeleteEvent) bool { return true },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+}
+
+// IgnoreAnnotationChanges filters update events that only change the given annotation,
+// e.g. the status annotation that is written by the controller itself.
+func IgnoreAnnotationChanges(annotation string) predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !apiequality.Semantic.DeepEqual(withoutAnnotation(e.ObjectOld, annotation), withoutAnnotation(e.ObjectNew, annotation))
+		},
+	}
+}
+
+// withoutAnnotation returns a copy of the object without the given annotation
+// and without the metadata that is changed by every update.
+func withoutAnnotation(obj client.Object, annotation string) client.Object {
+	obj = obj.DeepCopyObject().(client.Object)
+	annotations := obj.GetAnnotations()
+	delete(annotations, annotation)
+	obj.SetAnnotations(annotations)
+	obj.SetResourceVersion("")
+	obj.SetManagedFields(nil)
+	return obj
+}
@@ -0,0 +1,57 @@
+package predicates_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/controllers/predicates"
+)
+
+var _ = Describe("PodPullSecretsChanged", func() {
+
+	var pod *corev1.Pod
+
+	BeforeEach(func() {
+		pod = &corev1.Pod{}
+		pod.Name = "test"
+		pod.Namespace = "default"
+		pod.Annotations = map[string]string{
+			v1alpha1.SecretReplicationFromNamespaceAnnotation: "src",
+		}
+		pod.Spec.ServiceAccountName = "default"
+		pod.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry"}}
+	})
+
+	It("should let create and delete events pass", func() {
+		p := predicates.PodPullSecretsChanged()
+		Expect(p.Create(event.CreateEvent{Object: pod})).To(BeTrue())
+		Expect(p.Delete(event.DeleteEvent{Object: pod})).To(BeTrue())
+	})
+
+	It("should filter status updates", func() {
+		newPod := pod.DeepCopy()
+		newPod.Status.Phase = corev1.PodRunning
+		Expect(predicates.PodPullSecretsChanged().Update(event.UpdateEvent{ObjectOld: pod, ObjectNew: newPod})).To(BeFalse())
+	})
+
+	It("should let changes of the image pull secrets pass", func() {
+		newPod := pod.DeepCopy()
+		newPod.Spec.ImagePullSecrets = append(newPod.Spec.ImagePullSecrets, corev1.LocalObjectReference{Name: "other"})
+		Expect(predicates.PodPullSecretsChanged().Update(event.UpdateEvent{ObjectOld: pod, ObjectNew: newPod})).To(BeTrue())
+	})
+
+	It("should let changes of the service account pass", func() {
+		newPod := pod.DeepCopy()
+		newPod.Spec.ServiceAccountName = "other"
+		Expect(predicates.PodPullSecretsChanged().Update(event.UpdateEvent{ObjectOld: pod, ObjectNew: newPod})).To(BeTrue())
+	})
+
+	It("should let changes of the from-namespace annotation pass", func() {
+		newPod := pod.DeepCopy()
+		newPod.Annotations[v1alpha1.SecretReplicationFromNamespaceAnnotation] = "other"
+		Expect(predicates.PodPullSecretsChanged().Update(event.UpdateEvent{ObjectOld: pod, ObjectNew: newPod})).To(BeTrue())
+	})
+})
@@ -2,28 +2,26 @@ package secretctrl
 
 import (
 	"github.com/go-logr/logr"
-	"github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+	"github.com/schrodit/secret-replication-controller/pkg/clusters"
+	annotationctrl "github.com/schrodit/secret-replication-controller/pkg/controllers/annotation"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/runtime"
-	ctrl "sigs.k8s.io/controller-runtime"
+	"k8s.io/client-go/tools/record"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
-type secretController struct {
-	log    logr.Logger
-	client ctrlclient.Client
-	scheme *runtime.Scheme
-	*errors.ErrorReporter
+// New creates a new controller that replicates annotated secrets.
+func New(log logr.Logger, client ctrlclient.Client, eventRecorder record.EventRecorder) *annotationctrl.Controller {
+	return annotationctrl.New(log, client, eventRecorder, func() ctrlclient.Object {
+		return &corev1.Secret{}
+	})
 }
 
-// AddToMgr adds the secrets reconiler to the given manager
-func AddToMgr(log logr.Logger, mgr manager.Manager) error {
-	c := &secretController{
-		log:           log,
-		client:        mgr.GetClient(),
-		scheme:        mgr.GetScheme(),
-		ErrorReporter: errors.NewErrorReporter(mgr.GetEventRecorderFor("SecretReplicationSecretController")),
-	}
-	return ctrl.NewControllerManagedBy(mgr).For(&corev1.Secret{}).Complete(c)
+// AddToMgr adds the secrets reconiler to the given manager.
+// Sources are also replicated to remote clusters if a cluster cache is given.
+func AddToMgr(log logr.Logger, mgr manager.Manager, clusterCache *clusters.Cache, opts controller.Options) error {
+	return New(log, mgr.GetClient(), mgr.GetEventRecorderFor("SecretReplicationSecretController")).
+		WithClusters(clusterCache).
+		SetupWithManager(mgr, opts)
 }
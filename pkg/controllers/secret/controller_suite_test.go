@@ -1,12 +1,18 @@
 package secretctrl
 
 import (
+	"context"
 	"testing"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	annotationctrl "github.com/schrodit/secret-replication-controller/pkg/controllers/annotation"
 )
 
 func TestSuite(t *testing.T) {
@@ -17,6 +23,13 @@ func TestSuite(t *testing.T) {
 var (
 	testenv *envtest.Environment
 	client  ctrlclient.Client
+	// cachedClient reads from an informer cache with the field indexes of the controller
+	// like the client of a manager.
+	cachedClient ctrlclient.Client
+	stopCache    context.CancelFunc
+	// remoteTestenv is a second api server that is used as remote cluster.
+	remoteTestenv *envtest.Environment
+	remoteClient  ctrlclient.Client
 )
 
 var _ = BeforeSuite(func() {
@@ -27,8 +40,54 @@ var _ = BeforeSuite(func() {
 
 	client, err = ctrlclient.New(restConfig, ctrlclient.Options{})
 	Expect(err).ToNot(HaveOccurred())
+
+	informers, err := cache.New(restConfig, cache.Options{})
+	Expect(err).ToNot(HaveOccurred())
+	var ctx context.Context
+	ctx, stopCache = context.WithCancel(context.Background())
+	Expect(annotationctrl.IndexFields(ctx, informers, &corev1.Secret{})).To(Succeed())
+	go func() {
+		defer GinkgoRecover()
+		Expect(informers.Start(ctx)).To(Succeed())
+	}()
+	Expect(informers.WaitForCacheSync(ctx)).To(BeTrue())
+	cachedClient, err = ctrlclient.NewDelegatingClient(ctrlclient.NewDelegatingClientInput{
+		CacheReader: informers,
+		Client:      client,
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	remoteTestenv = &envtest.Environment{}
+
+	remoteRestConfig, err := remoteTestenv.Start()
+	Expect(err).ToNot(HaveOccurred())
+
+	remoteClient, err = ctrlclient.New(remoteRestConfig, ctrlclient.Options{})
+	Expect(err).ToNot(HaveOccurred())
 })
 
 var _ = AfterSuite(func() {
+	stopCache()
 	Expect(testenv.Stop()).To(Succeed())
+	Expect(remoteTestenv.Stop()).To(Succeed())
 })
+
+// expectCached waits until the cached client has observed the given version of the object.
+func expectCached(obj ctrlclient.Object) {
+	cached := obj.DeepCopyObject().(ctrlclient.Object)
+	Eventually(func() string {
+		if err := cachedClient.Get(context.Background(), ctrlclient.ObjectKeyFromObject(obj), cached); err != nil {
+			return ""
+		}
+		return cached.GetResourceVersion()
+	}).Should(Equal(obj.GetResourceVersion()))
+}
+
+// receivedEvents returns all events that have been recorded by the fake recorder so far.
+func receivedEvents(recorder *record.FakeRecorder) []string {
+	events := make([]string, 0)
+	for len(recorder.Events) != 0 {
+		events = append(events, <-recorder.Events)
+	}
+	return events
+}
@@ -3,16 +3,31 @@ package secretctrl
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
-	"github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1/helper"
+	"github.com/schrodit/secret-replication-controller/pkg/clusters"
+	annotationctrl "github.com/schrodit/secret-replication-controller/pkg/controllers/annotation"
+	interrors "github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+	"github.com/schrodit/secret-replication-controller/pkg/metrics"
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/client-go/tools/record"
 	ctrlruntime "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -20,7 +35,7 @@ import (
 var _ = Describe("controller", func() {
 
 	var (
-		ctrl       *secretController
+		ctrl       *annotationctrl.Controller
 		secret     *corev1.Secret
 		namespaces []string
 	)
@@ -36,16 +51,17 @@ var _ = Describe("controller", func() {
 		Expect(client.Create(context.TODO(), secret)).To(Succeed())
 		namespaces = make([]string, 0)
 
-		ctrl = &secretController{
-			log:           logr.Discard(),
-			client:        client,
-			ErrorReporter: errors.NewErrorReporter(record.NewFakeRecorder(1024)),
-		}
+		ctrl = New(logr.Discard(), client, record.NewFakeRecorder(1024))
 	})
 
 	AfterEach(func() {
 		ctx := context.Background()
-		Expect(client.Delete(ctx, secret)).To(Succeed())
+		// remove the finalizer so that the source secret is actually deleted
+		if err := client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, secret); err == nil {
+			controllerutil.RemoveFinalizer(secret, v1alpha1.SecretReplicationFinalizer)
+			Expect(client.Update(ctx, secret)).To(Succeed())
+			Expect(ctrlclient.IgnoreNotFound(client.Delete(ctx, secret))).To(Succeed())
+		}
 
 		for _, ns := range namespaces {
 			namespace := &corev1.Namespace{}
@@ -80,6 +96,230 @@ var _ = Describe("controller", func() {
 			Expect(newSecret.Annotations[v1alpha1.SecretReplicationLastObservedHashAnnotation]).ToNot(Equal(""))
 		})
 
+		It("should report the replication status in the status annotation", func() {
+			ctx := context.Background()
+
+			By("create test namespace")
+			ns := &corev1.Namespace{}
+			ns.GenerateName = "e2e-"
+			Expect(client.Create(ctx, ns))
+			namespaces = append(namespaces, ns.Name)
+
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationNamespacesAnnotation: ns.Name,
+			}
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			newSecret := &corev1.Secret{}
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
+
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, secret)).To(Succeed())
+			statuses, err := helper.GetReplicationStatus(secret)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(statuses).To(HaveLen(1))
+			Expect(statuses[0].Namespace).To(Equal(ns.Name))
+			Expect(statuses[0].LastSyncedHash).To(Equal(newSecret.Annotations[v1alpha1.SecretReplicationLastObservedHashAnnotation]))
+			Expect(statuses[0].LastSyncTime).ToNot(BeNil())
+			Expect(statuses[0].Reason).To(BeEmpty())
+
+			By("not update the status if nothing has changed")
+			resourceVersion := secret.ResourceVersion
+			_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, secret)).To(Succeed())
+			Expect(secret.ResourceVersion).To(Equal(resourceVersion))
+		})
+
+		It("should record the replication in the metrics", func() {
+			ctx := context.Background()
+
+			By("create test namespace")
+			ns := &corev1.Namespace{}
+			ns.GenerateName = "e2e-"
+			Expect(client.Create(ctx, ns))
+			namespaces = append(namespaces, ns.Name)
+
+			created := counterValue(metrics.Replications.WithLabelValues("secret", "secret", string(replicator.Created), ""))
+
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationNamespacesAnnotation: ns.Name,
+			}
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(counterValue(metrics.Replications.WithLabelValues("secret", "secret", string(replicator.Created), ""))).To(Equal(created + 1))
+			Expect(gaugeValue(metrics.ManagedReplicas.WithLabelValues("secret", "secret", secret.Namespace, secret.Name))).To(Equal(float64(1)))
+		})
+
+		It("should handle existing secrets that are not replicas according to the conflict policy", func() {
+			ctx := context.Background()
+
+			By("create test namespace with an unmanaged secret")
+			ns := &corev1.Namespace{}
+			ns.GenerateName = "e2e-"
+			Expect(client.Create(ctx, ns))
+			namespaces = append(namespaces, ns.Name)
+
+			existing := &corev1.Secret{}
+			existing.Name = secret.Name
+			existing.Namespace = ns.Name
+			existing.Data = map[string][]byte{
+				"key": []byte("unmanaged"),
+			}
+			Expect(client.Create(ctx, existing)).To(Succeed())
+
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationNamespacesAnnotation: ns.Name,
+			}
+			Expect(client.Update(ctx, secret)).To(Succeed())
+			skipped := counterValue(metrics.Replications.WithLabelValues("secret", "secret", string(replicator.Skipped), string(interrors.Conflict)))
+
+			_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.Get(ctx, types.NamespacedName{Name: existing.Name, Namespace: ns.Name}, existing)).To(Succeed())
+			Expect(existing.Data).To(HaveKeyWithValue("key", []byte("unmanaged")))
+			Expect(counterValue(metrics.Replications.WithLabelValues("secret", "secret", string(replicator.Skipped), string(interrors.Conflict)))).To(Equal(skipped + 1))
+
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, secret)).To(Succeed())
+			statuses, err := helper.GetReplicationStatus(secret)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(statuses).To(HaveLen(1))
+			Expect(statuses[0].Reason).To(Equal(string(replicator.Skipped)))
+			Expect(statuses[0].Message).To(Equal("a secret that is not a replica of the source already exists"))
+
+			By("adopt the unmanaged secret")
+			secret.Annotations[v1alpha1.SecretReplicationConflictPolicyAnnotation] = string(replicator.ConflictPolicyAdopt)
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.Get(ctx, types.NamespacedName{Name: existing.Name, Namespace: ns.Name}, existing)).To(Succeed())
+			Expect(existing.Data).To(Equal(secret.Data))
+			Expect(existing.Annotations).To(HaveKeyWithValue(v1alpha1.SecretReplicationReplicaOfAnnotation, replicator.ReplicaOf(secret)))
+		})
+
+		It("should preserve the type, labels and allow-listed annotations of the secret", func() {
+			ctx := context.Background()
+
+			By("create test namespace")
+			ns := &corev1.Namespace{}
+			ns.GenerateName = "e2e-"
+			Expect(client.Create(ctx, ns))
+			namespaces = append(namespaces, ns.Name)
+
+			By("create a tls secret")
+			Expect(client.Delete(ctx, secret)).To(Succeed())
+			secret = &corev1.Secret{}
+			secret.GenerateName = "e2e-"
+			secret.Namespace = "default"
+			secret.Type = corev1.SecretTypeTLS
+			secret.Data = map[string][]byte{
+				corev1.TLSCertKey:       []byte("cert"),
+				corev1.TLSPrivateKeyKey: []byte("key"),
+			}
+			secret.Labels = map[string]string{
+				"app": "test",
+			}
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationNamespacesAnnotation:          ns.Name,
+				v1alpha1.SecretReplicationPreserveAnnotationsAnnotation: "example.com/keep, other.com/*",
+				"example.com/keep":   "a",
+				"other.com/some":     "b",
+				"example.com/ignore": "c",
+			}
+			Expect(client.Create(ctx, secret)).To(Succeed())
+
+			_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			newSecret := &corev1.Secret{}
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
+			Expect(newSecret.Type).To(Equal(corev1.SecretTypeTLS))
+			Expect(newSecret.Labels).To(HaveKeyWithValue("app", "test"))
+			Expect(newSecret.Annotations).To(HaveKeyWithValue("example.com/keep", "a"))
+			Expect(newSecret.Annotations).To(HaveKeyWithValue("other.com/some", "b"))
+			Expect(newSecret.Annotations).ToNot(HaveKey("example.com/ignore"))
+			Expect(newSecret.Annotations).ToNot(HaveKey(v1alpha1.SecretReplicationNamespacesAnnotation))
+
+			By("propagate label changes")
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, secret)).To(Succeed())
+			secret.Labels = map[string]string{
+				"app": "other",
+			}
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
+			Expect(newSecret.Labels).To(HaveKeyWithValue("app", "other"))
+		})
+
+		It("should rename the replica and project the data keys", func() {
+			ctx := context.Background()
+
+			By("create test namespace")
+			ns := &corev1.Namespace{}
+			ns.GenerateName = "e2e-"
+			Expect(client.Create(ctx, ns))
+			namespaces = append(namespaces, ns.Name)
+
+			secret.Data = map[string][]byte{
+				"tls.crt": []byte("cert"),
+				"tls.key": []byte("key"),
+				"other":   []byte("other"),
+			}
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationNamespacesAnnotation:  ns.Name,
+				v1alpha1.SecretReplicationTargetNameAnnotation:  "ca-bundle",
+				v1alpha1.SecretReplicationKeyMappingAnnotation:  "tls.crt=ca.crt",
+				v1alpha1.SecretReplicationExcludeKeysAnnotation: "tls.key",
+			}
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			newSecret := &corev1.Secret{}
+			Expect(client.Get(ctx, types.NamespacedName{Name: "ca-bundle", Namespace: ns.Name}, newSecret)).To(Succeed())
+			Expect(newSecret.Data).To(Equal(map[string][]byte{
+				"ca.crt": []byte("cert"),
+				"other":  []byte("other"),
+			}))
+
+			By("only copy the included keys")
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, secret)).To(Succeed())
+			secret.Annotations[v1alpha1.SecretReplicationIncludeKeysAnnotation] = "tls.crt"
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.Get(ctx, types.NamespacedName{Name: "ca-bundle", Namespace: ns.Name}, newSecret)).To(Succeed())
+			Expect(newSecret.Data).To(Equal(map[string][]byte{
+				"ca.crt": []byte("cert"),
+			}))
+
+			By("delete the replica with the old name if the target name changes")
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, secret)).To(Succeed())
+			secret.Annotations[v1alpha1.SecretReplicationTargetNameAnnotation] = "other-bundle"
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.Get(ctx, types.NamespacedName{Name: "other-bundle", Namespace: ns.Name}, newSecret)).To(Succeed())
+			err = client.Get(ctx, types.NamespacedName{Name: "ca-bundle", Namespace: ns.Name}, newSecret)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
 		It("should create a replicated secret in one namespace using a custom prefix", func() {
 			ctx := context.Background()
 
@@ -152,6 +392,105 @@ var _ = Describe("controller", func() {
 			Expect(newSecret.Annotations[v1alpha1.SecretReplicationLastObservedHashAnnotation]).ToNot(Equal(""))
 		})
 
+		It("should create replicated secrets in all namespaces matching the namespace selector", func() {
+			ctx := context.Background()
+
+			By("create test namespaces")
+			ns1 := &corev1.Namespace{}
+			ns1.GenerateName = "e2e-"
+			ns1.Labels = map[string]string{"team": "payments"}
+			Expect(client.Create(ctx, ns1))
+			namespaces = append(namespaces, ns1.Name)
+
+			ns2 := &corev1.Namespace{}
+			ns2.GenerateName = "e2e-"
+			Expect(client.Create(ctx, ns2))
+			namespaces = append(namespaces, ns2.Name)
+
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationNamespaceSelectorAnnotation: "team=payments",
+			}
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			newSecret := &corev1.Secret{}
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns1.Name}, newSecret)).To(Succeed())
+			Expect(newSecret.Data).To(Equal(secret.Data))
+
+			err = client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns2.Name}, newSecret)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should create replicated secrets in all namespaces matching a pattern except excluded ones", func() {
+			ctx := context.Background()
+
+			By("create test namespaces")
+			ns1 := &corev1.Namespace{}
+			ns1.GenerateName = "e2e-pattern-"
+			Expect(client.Create(ctx, ns1))
+			namespaces = append(namespaces, ns1.Name)
+
+			ns2 := &corev1.Namespace{}
+			ns2.GenerateName = "e2e-pattern-"
+			Expect(client.Create(ctx, ns2))
+			namespaces = append(namespaces, ns2.Name)
+
+			ns3 := &corev1.Namespace{}
+			ns3.GenerateName = "e2e-"
+			Expect(client.Create(ctx, ns3))
+			namespaces = append(namespaces, ns3.Name)
+
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationNamespacesAnnotation:        "e2e-pattern-*",
+				v1alpha1.SecretReplicationExcludeNamespacesAnnotation: ns2.Name,
+			}
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			newSecret := &corev1.Secret{}
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns1.Name}, newSecret)).To(Succeed())
+			Expect(newSecret.Data).To(Equal(secret.Data))
+
+			err = client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns2.Name}, newSecret)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			err = client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns3.Name}, newSecret)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should create replicated secrets in parallel", func() {
+			ctx := context.Background()
+			defer func(concurrency int) {
+				replicator.MaxConcurrentReplications = concurrency
+			}(replicator.MaxConcurrentReplications)
+			replicator.MaxConcurrentReplications = 3
+
+			By("create test namespaces")
+			for i := 0; i < 5; i++ {
+				ns := &corev1.Namespace{}
+				ns.GenerateName = "e2e-"
+				Expect(client.Create(ctx, ns)).To(Succeed())
+				namespaces = append(namespaces, ns.Name)
+			}
+
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationNamespacesAnnotation: strings.Join(namespaces, ","),
+			}
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, ns := range namespaces {
+				newSecret := &corev1.Secret{}
+				Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns}, newSecret)).To(Succeed())
+				Expect(newSecret.Data).To(Equal(secret.Data))
+			}
+		})
+
 		It("should update an existing secret when data of the source is updated", func() {
 			ctx := context.Background()
 
@@ -173,6 +512,8 @@ var _ = Describe("controller", func() {
 			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
 			Expect(newSecret.Data).To(Equal(secret.Data))
 
+			// the controller adds a finalizer so the source secret has to be refetched
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, secret)).To(Succeed())
 			secret.Data = nil
 			Expect(client.Update(ctx, secret)).To(Succeed())
 
@@ -182,6 +523,504 @@ var _ = Describe("controller", func() {
 			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
 			Expect(newSecret.Data).To(BeNil())
 		})
+
+		It("should not remove labels and annotations of other tools from the replica", func() {
+			ctx := context.Background()
+
+			By("create test namespace")
+			ns := &corev1.Namespace{}
+			ns.GenerateName = "e2e-"
+			Expect(client.Create(ctx, ns))
+			namespaces = append(namespaces, ns.Name)
+
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationNamespacesAnnotation: ns.Name,
+			}
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			By("add a label and an annotation to the replica")
+			replica := &corev1.Secret{}
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, replica)).To(Succeed())
+			replica.Labels = map[string]string{
+				"other-tool": "true",
+			}
+			replica.Annotations["example.com/other-tool"] = "true"
+			Expect(client.Update(ctx, replica)).To(Succeed())
+
+			By("update the source")
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, secret)).To(Succeed())
+			secret.Data = map[string][]byte{
+				"key": []byte("updated"),
+			}
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, replica)).To(Succeed())
+			Expect(replica.Data).To(Equal(secret.Data))
+			Expect(replica.Labels).To(HaveKeyWithValue("other-tool", "true"))
+			Expect(replica.Annotations).To(HaveKeyWithValue("example.com/other-tool", "true"))
+			Expect(replica.Annotations).To(HaveKeyWithValue(v1alpha1.SecretReplicationReplicaOfAnnotation, replicator.ReplicaOf(secret)))
+		})
+
+		It("should restore the data of a replica that has been changed", func() {
+			ctx := context.Background()
+			recorder := record.NewFakeRecorder(1024)
+			ctrl = New(logr.Discard(), client, recorder)
+
+			By("create test namespace")
+			ns := &corev1.Namespace{}
+			ns.GenerateName = "e2e-"
+			Expect(client.Create(ctx, ns))
+			namespaces = append(namespaces, ns.Name)
+
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationNamespacesAnnotation: ns.Name,
+			}
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			By("change the data of the replica")
+			replica := &corev1.Secret{}
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, replica)).To(Succeed())
+			Expect(ctrl.MapReplica(replica)).To(ConsistOf(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace},
+			}))
+			replica.Data = map[string][]byte{
+				"key": []byte("changed"),
+			}
+			Expect(client.Update(ctx, replica)).To(Succeed())
+
+			_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, replica)).To(Succeed())
+			Expect(replica.Data).To(Equal(secret.Data))
+			Expect(receivedEvents(recorder)).To(ContainElement(ContainSubstring("Corrected drift of replica in namespace %s", ns.Name)))
+		})
+
+		It("should remove data keys from the replica that are not part of the source", func() {
+			ctx := context.Background()
+
+			By("create test namespace")
+			ns := &corev1.Namespace{}
+			ns.GenerateName = "e2e-"
+			Expect(client.Create(ctx, ns))
+			namespaces = append(namespaces, ns.Name)
+
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationNamespacesAnnotation: ns.Name,
+			}
+			secret.Data["revoked"] = []byte("credential")
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			By("add a key to the replica with another field manager")
+			replica := &corev1.Secret{}
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, replica)).To(Succeed())
+			replica.Data["other"] = []byte("value")
+			Expect(client.Update(ctx, replica)).To(Succeed())
+
+			By("remove a key from the source")
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, secret)).To(Succeed())
+			delete(secret.Data, "revoked")
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, replica)).To(Succeed())
+			Expect(replica.Data).To(Equal(secret.Data))
+		})
+
+		It("should delete the replicated secrets when the source secret is deleted", func() {
+			ctx := context.Background()
+
+			By("create test namespace")
+			ns := &corev1.Namespace{}
+			ns.GenerateName = "e2e-"
+			Expect(client.Create(ctx, ns))
+			namespaces = append(namespaces, ns.Name)
+
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationNamespacesAnnotation: ns.Name,
+			}
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, secret)).To(Succeed())
+			Expect(secret.Finalizers).To(ContainElement(v1alpha1.SecretReplicationFinalizer))
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, &corev1.Secret{})).To(Succeed())
+
+			Expect(client.Delete(ctx, secret)).To(Succeed())
+			_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, &corev1.Secret{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			err = client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, &corev1.Secret{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should delete a replicated secret when its namespace is removed from the annotation", func() {
+			ctx := context.Background()
+
+			By("create test namespaces")
+			ns1 := &corev1.Namespace{}
+			ns1.GenerateName = "e2e-"
+			Expect(client.Create(ctx, ns1))
+			namespaces = append(namespaces, ns1.Name)
+
+			ns2 := &corev1.Namespace{}
+			ns2.GenerateName = "e2e-"
+			Expect(client.Create(ctx, ns2))
+			namespaces = append(namespaces, ns2.Name)
+
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationNamespacesAnnotation: fmt.Sprintf("%s,%s", ns1.Name, ns2.Name),
+			}
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns2.Name}, &corev1.Secret{})).To(Succeed())
+
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, secret)).To(Succeed())
+			secret.Annotations[v1alpha1.SecretReplicationNamespacesAnnotation] = ns1.Name
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns1.Name}, &corev1.Secret{})).To(Succeed())
+			err = client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns2.Name}, &corev1.Secret{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should not delete replicas that are referenced by other kinds", func() {
+			ctx := context.Background()
+
+			By("create test namespaces")
+			ns1 := &corev1.Namespace{}
+			ns1.GenerateName = "e2e-"
+			Expect(client.Create(ctx, ns1))
+			namespaces = append(namespaces, ns1.Name)
+
+			ns2 := &corev1.Namespace{}
+			ns2.GenerateName = "e2e-"
+			Expect(client.Create(ctx, ns2))
+			namespaces = append(namespaces, ns2.Name)
+
+			By("replicate the secret for an ingress in both namespaces")
+			_, err := replicator.New(client, secret).ReferencedBy("ingress").ReplicateTo(ctx, ns1.Name)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = replicator.New(client, secret).ReferencedBy("ingress").ReplicateTo(ctx, ns2.Name)
+			Expect(err).ToNot(HaveOccurred())
+
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationNamespacesAnnotation: ns1.Name,
+			}
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			replica := &corev1.Secret{}
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns1.Name}, replica)).To(Succeed())
+			Expect(replicator.Referrers(replica).List()).To(ConsistOf("ingress", replicator.AnnotationReferrer))
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns2.Name}, replica)).To(Succeed())
+			Expect(replicator.Referrers(replica).List()).To(ConsistOf("ingress"))
+
+			By("remove the replication annotations")
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, secret)).To(Succeed())
+			delete(secret.Annotations, v1alpha1.SecretReplicationNamespacesAnnotation)
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns1.Name}, replica)).To(Succeed())
+			Expect(replicator.Referrers(replica).List()).To(ConsistOf("ingress"))
+		})
+	})
+
+	Context("namespace watch", func() {
+
+		var mapCtrl *annotationctrl.Controller
+
+		BeforeEach(func() {
+			mapCtrl = New(logr.Discard(), cachedClient, record.NewFakeRecorder(1024))
+		})
+
+		It("should enqueue secrets that are replicated to all namespaces", func() {
+			ctx := context.Background()
+
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationAllNamespacesAnnotation: "true",
+			}
+			Expect(client.Update(ctx, secret)).To(Succeed())
+			expectCached(secret)
+
+			ns := &corev1.Namespace{}
+			ns.Name = "new-namespace"
+			Expect(mapCtrl.MapNamespace(ns)).To(ContainElement(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace},
+			}))
+		})
+
+		It("should enqueue secrets whose namespace selector matches the namespace", func() {
+			ctx := context.Background()
+
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationNamespaceSelectorAnnotation: "team=payments",
+			}
+			Expect(client.Update(ctx, secret)).To(Succeed())
+			expectCached(secret)
+
+			ns := &corev1.Namespace{}
+			ns.Name = "new-namespace"
+			ns.Labels = map[string]string{"team": "payments"}
+			Expect(mapCtrl.MapNamespace(ns)).To(ContainElement(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace},
+			}))
+
+			ns.Labels = map[string]string{"team": "other"}
+			Expect(mapCtrl.MapNamespace(ns)).ToNot(ContainElement(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace},
+			}))
+		})
+
+		It("should enqueue secrets that have a replica in a namespace whose labels do not match anymore", func() {
+			ctx := context.Background()
+
+			ns := &corev1.Namespace{}
+			ns.GenerateName = "e2e-"
+			ns.Labels = map[string]string{"team": "payments"}
+			Expect(client.Create(ctx, ns)).To(Succeed())
+			namespaces = append(namespaces, ns.Name)
+
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationNamespaceSelectorAnnotation: "team=payments",
+			}
+			Expect(client.Update(ctx, secret)).To(Succeed())
+			_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+			replica := &corev1.Secret{}
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, replica)).To(Succeed())
+			expectCached(replica)
+
+			ns.Labels = map[string]string{"team": "other"}
+			Expect(client.Update(ctx, ns)).To(Succeed())
+			Expect(mapCtrl.MapNamespace(ns)).To(ContainElement(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace},
+			}))
+		})
+
+		It("should not enqueue secrets that exclude the namespace", func() {
+			ctx := context.Background()
+
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationAllNamespacesAnnotation:     "true",
+				v1alpha1.SecretReplicationExcludeNamespacesAnnotation: "kube-*",
+			}
+			Expect(client.Update(ctx, secret)).To(Succeed())
+			expectCached(secret)
+
+			ns := &corev1.Namespace{}
+			ns.Name = "kube-new"
+			Expect(mapCtrl.MapNamespace(ns)).ToNot(ContainElement(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace},
+			}))
+		})
+
+		It("should only enqueue secrets that are replicated to the created namespace", func() {
+			ctx := context.Background()
+
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationNamespacesAnnotation: "a,new-namespace",
+			}
+			Expect(client.Update(ctx, secret)).To(Succeed())
+			expectCached(secret)
+
+			ns := &corev1.Namespace{}
+			ns.Name = "new-namespace"
+			Expect(mapCtrl.MapNamespace(ns)).To(ContainElement(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace},
+			}))
+
+			ns.Name = "other-namespace"
+			Expect(mapCtrl.MapNamespace(ns)).ToNot(ContainElement(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace},
+			}))
+		})
+	})
+
+	Context("pull", func() {
+
+		var dst *corev1.Secret
+
+		BeforeEach(func() {
+			ctx := context.Background()
+			ns := &corev1.Namespace{}
+			ns.GenerateName = "e2e-"
+			Expect(client.Create(ctx, ns)).To(Succeed())
+			namespaces = append(namespaces, ns.Name)
+
+			dst = &corev1.Secret{}
+			dst.Name = "pulled"
+			dst.Namespace = ns.Name
+			dst.Annotations = map[string]string{
+				v1alpha1.SecretReplicationReplicateFromAnnotation: fmt.Sprintf("%s/%s", secret.Namespace, secret.Name),
+			}
+			Expect(client.Create(ctx, dst)).To(Succeed())
+		})
+
+		It("should fill a secret with the data of an allowed source", func() {
+			ctx := context.Background()
+
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationAllowedNamespacesAnnotation: "e2e-*",
+			}
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: dst.Name, Namespace: dst.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.Get(ctx, types.NamespacedName{Name: dst.Name, Namespace: dst.Namespace}, dst)).To(Succeed())
+			Expect(dst.Data).To(Equal(secret.Data))
+			Expect(dst.Annotations).To(HaveKey(v1alpha1.SecretReplicationLastObservedHashAnnotation))
+			Expect(dst.Annotations).ToNot(HaveKey(v1alpha1.SecretReplicationReplicaOfAnnotation))
+
+			By("map the source to the pulling secret")
+			expectCached(dst)
+			mapCtrl := New(logr.Discard(), cachedClient, record.NewFakeRecorder(1024))
+			Expect(mapCtrl.MapSource(secret)).To(ContainElement(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: dst.Name, Namespace: dst.Namespace},
+			}))
+		})
+
+		It("should remove keys of the pulling secret that are not part of the source", func() {
+			ctx := context.Background()
+
+			dst.Data = map[string][]byte{
+				"placeholder": []byte("value"),
+			}
+			Expect(client.Update(ctx, dst)).To(Succeed())
+
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationAllowedNamespacesAnnotation: "e2e-*",
+			}
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: dst.Name, Namespace: dst.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.Get(ctx, types.NamespacedName{Name: dst.Name, Namespace: dst.Namespace}, dst)).To(Succeed())
+			Expect(dst.Data).ToNot(HaveKey("placeholder"))
+			Expect(dst.Data).To(Equal(secret.Data))
+		})
+
+		It("should not fill a secret if the source does not allow the namespace", func() {
+			ctx := context.Background()
+
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationAllowedNamespacesAnnotation: "other",
+			}
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: dst.Name, Namespace: dst.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.Get(ctx, types.NamespacedName{Name: dst.Name, Namespace: dst.Namespace}, dst)).To(Succeed())
+			Expect(dst.Data).To(BeEmpty())
+		})
+
+	})
+
+	Context("remote clusters", func() {
+
+		var clusterSecret *corev1.Secret
+
+		BeforeEach(func() {
+			kubeconfig, err := kubeconfigFromRestConfig(remoteTestenv.Config)
+			Expect(err).ToNot(HaveOccurred())
+
+			clusterSecret = &corev1.Secret{}
+			clusterSecret.GenerateName = "cluster-"
+			clusterSecret.Namespace = "default"
+			clusterSecret.Data = map[string][]byte{
+				clusters.KubeconfigKey: kubeconfig,
+			}
+			Expect(client.Create(context.TODO(), clusterSecret)).To(Succeed())
+
+			ctrl.WithClusters(clusters.NewCache(client, scheme.Scheme, "default"))
+		})
+
+		AfterEach(func() {
+			Expect(client.Delete(context.TODO(), clusterSecret)).To(Succeed())
+		})
+
+		It("should replicate a secret to a remote cluster and delete it if the cluster is removed", func() {
+			ctx := context.Background()
+			recorder := record.NewFakeRecorder(1024)
+			ctrl = New(logr.Discard(), client, recorder).WithClusters(clusters.NewCache(client, scheme.Scheme, "default"))
+
+			By("create test namespace that only exists in the remote cluster")
+			ns := &corev1.Namespace{}
+			ns.GenerateName = "e2e-"
+			Expect(remoteClient.Create(ctx, ns)).To(Succeed())
+			defer func() {
+				Expect(remoteClient.Delete(ctx, ns)).To(Succeed())
+			}()
+
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationNamespacesAnnotation: ns.Name,
+				v1alpha1.SecretReplicationClustersAnnotation:   clusterSecret.Name,
+			}
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			newSecret := &corev1.Secret{}
+			Expect(remoteClient.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
+			Expect(newSecret.Data).To(Equal(secret.Data))
+
+			By("report that the namespace does not exist in the local cluster")
+			Expect(receivedEvents(recorder)).To(ContainElement(HavePrefix(fmt.Sprintf("%s %s", corev1.EventTypeWarning, interrors.InvalidNamespace))))
+			err = client.Get(ctx, types.NamespacedName{Name: ns.Name}, &corev1.Namespace{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, secret)).To(Succeed())
+			statuses, err := helper.GetReplicationStatus(secret)
+			Expect(err).ToNot(HaveOccurred())
+			clusterNames := make([]string, 0)
+			for _, status := range statuses {
+				clusterNames = append(clusterNames, status.Cluster)
+			}
+			Expect(clusterNames).To(ContainElement(clusterSecret.Name))
+
+			By("remove the cluster from the annotation")
+			delete(secret.Annotations, v1alpha1.SecretReplicationClustersAnnotation)
+			Expect(client.Update(ctx, secret)).To(Succeed())
+
+			_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = remoteClient.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
 	})
 
 	Context("e2e", func() {
@@ -233,6 +1072,8 @@ var _ = Describe("controller", func() {
 				return newSecret.Data
 			}).Should(Equal(secret.Data))
 
+			// the controller adds a finalizer so the source secret has to be refetched
+			Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, secret)).To(Succeed())
 			secret.Data = nil
 			Expect(client.Update(ctx, secret)).To(Succeed())
 
@@ -248,3 +1089,35 @@ var _ = Describe("controller", func() {
 	})
 
 })
+
+// kubeconfigFromRestConfig creates a kubeconfig that can be used to access the api server of the given rest config.
+func kubeconfigFromRestConfig(restConfig *rest.Config) ([]byte, error) {
+	config := clientcmdapi.NewConfig()
+	config.Clusters["default"] = &clientcmdapi.Cluster{
+		Server:                   restConfig.Host,
+		CertificateAuthorityData: restConfig.CAData,
+	}
+	config.AuthInfos["default"] = &clientcmdapi.AuthInfo{
+		ClientCertificateData: restConfig.CertData,
+		ClientKeyData:         restConfig.KeyData,
+		Token:                 restConfig.BearerToken,
+	}
+	config.Contexts["default"] = &clientcmdapi.Context{
+		Cluster:  "default",
+		AuthInfo: "default",
+	}
+	config.CurrentContext = "default"
+	return clientcmd.Write(*config)
+}
+
+func counterValue(counter prometheus.Counter) float64 {
+	m := &dto.Metric{}
+	Expect(counter.Write(m)).To(Succeed())
+	return m.GetCounter().GetValue()
+}
+
+func gaugeValue(gauge prometheus.Gauge) float64 {
+	m := &dto.Metric{}
+	Expect(gauge.Write(m)).To(Succeed())
+	return m.GetGauge().GetValue()
+}
@@ -0,0 +1,91 @@
+package serviceaccountctrl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+	"github.com/schrodit/secret-replication-controller/pkg/controllers/predicates"
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// PullSecretController replicates the image pull secrets that are referenced by service accounts or pods.
+type PullSecretController struct {
+	log       logr.Logger
+	client    ctrlclient.Client
+	name      string
+	newObject func() ctrlclient.Object
+	*errors.ErrorReporter
+}
+
+// NewServiceAccountController creates a new controller that replicates the image pull secrets of service accounts.
+func NewServiceAccountController(log logr.Logger, client ctrlclient.Client, eventRecorder record.EventRecorder) *PullSecretController {
+	return newController(log, client, eventRecorder, "serviceaccount", func() ctrlclient.Object { return &corev1.ServiceAccount{} })
+}
+
+// NewPodController creates a new controller that replicates the image pull secrets of pods.
+func NewPodController(log logr.Logger, client ctrlclient.Client, eventRecorder record.EventRecorder) *PullSecretController {
+	return newController(log, client, eventRecorder, "pod", func() ctrlclient.Object { return &corev1.Pod{} })
+}
+
+// newController creates a new image pull secret controller.
+// The name identifies the controller in metrics and equals the referrer kind that marks its replicas.
+func newController(log logr.Logger, client ctrlclient.Client, eventRecorder record.EventRecorder, name string, newObject func() ctrlclient.Object) *PullSecretController {
+	return &PullSecretController{
+		log:           log,
+		client:        client,
+		name:          name,
+		newObject:     newObject,
+		ErrorReporter: errors.NewErrorReporter(eventRecorder),
+	}
+}
+
+// AddToMgr adds the service account reconciler to the given manager.
+// If withPods is set, the image pull secrets of pods are replicated as well.
+func AddToMgr(log logr.Logger, mgr ctrl.Manager, withPods bool, opts controller.Options) error {
+	c := NewServiceAccountController(log, mgr.GetClient(), mgr.GetEventRecorderFor("SecretReplicationServiceAccountController"))
+	if err := IndexFields(context.Background(), mgr.GetFieldIndexer(), &corev1.ServiceAccount{}); err != nil {
+		return err
+	}
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.ServiceAccount{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(c.MapSecret)).
+		WithOptions(opts).
+		Complete(c)
+	if err != nil {
+		return err
+	}
+	if !withPods {
+		return nil
+	}
+
+	podCtrl := NewPodController(log, mgr.GetClient(), mgr.GetEventRecorderFor("SecretReplicationPodController"))
+	if err := IndexFields(context.Background(), mgr.GetFieldIndexer(), &corev1.Pod{}); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Pod{}, builder.WithPredicates(predicates.PodPullSecretsChanged())).
+		Watches(&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(podCtrl.MapSecret)).
+		WithOptions(opts).
+		Complete(podCtrl)
+}
+
+// IndexFields indexes the given kind of service accounts or pods by the image pull secrets they reference,
+// see replicator.SourceSecretIndex.
+func IndexFields(ctx context.Context, indexer ctrlclient.FieldIndexer, obj ctrlclient.Object) error {
+	if err := indexer.IndexField(ctx, obj, replicator.SourceSecretIndex, sourceSecrets); err != nil {
+		return fmt.Errorf("unable to index %T by source secret: %w", obj, err)
+	}
+	return nil
+}
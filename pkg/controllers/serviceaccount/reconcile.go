@@ -0,0 +1,169 @@
+package serviceaccountctrl
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/go-logr/logr"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1/helper"
+	interrors "github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+)
+
+func (c *PullSecretController) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	ctx = logr.NewContext(ctx, c.log.WithValues("name", req.Name, "namespace", req.Namespace))
+	obj := c.newObject()
+	if err := c.client.Get(ctx, req.NamespacedName, obj); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		// the object has been deleted so its image pull secrets may not be needed anymore
+		return reconcile.Result{}, c.deleteUnreferencedReplicas(ctx, req.Namespace)
+	}
+
+	if err := c.reconcile(ctx, obj); err != nil {
+		return reconcile.Result{}, err
+	}
+	// the image pull secrets of the object may have changed
+	if err := c.deleteUnreferencedReplicas(ctx, req.Namespace); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+func (c *PullSecretController) reconcile(ctx context.Context, obj client.Object) error {
+	log := logr.FromContextOrDiscard(ctx)
+	log.V(10).Info("check replication of image pull secrets")
+	srcNamespace, ok := helper.GetAnnotation(obj, v1alpha1.SecretReplicationFromNamespaceAnnotations)
+	if !ok {
+		log.V(10).Info("object not applicable for replication")
+		return nil
+	}
+
+	usedSecrets := ImagePullSecrets(obj)
+	if len(usedSecrets) == 0 {
+		log.V(10).Info("no image pull secrets used")
+		return nil
+	}
+
+	// check if defined namespace exists
+	if err := c.client.Get(ctx, client.ObjectKey{Name: srcNamespace}, &corev1.Namespace{}); err != nil {
+		return c.Report(ctx, fmt.Errorf("namespace %q not found", srcNamespace))
+	}
+
+	allErrs := interrors.ErrorList{}
+	results := replicator.ReplicateReferencedSecrets(ctx, c.client, obj, srcNamespace, usedSecrets)
+	for i, res := range results {
+		if res.Err != nil {
+			allErrs = append(allErrs, res.Err)
+			continue
+		}
+		if res.Changed() {
+			c.Event(obj, string(res.Action), fmt.Sprintf("%s replica of secret %s/%s", res.Action, srcNamespace, usedSecrets[i]))
+		}
+	}
+
+	replicator.RecordResults(c.name, &corev1.Secret{}, results)
+
+	return c.Report(ctx, allErrs)
+}
+
+// deleteUnreferencedReplicas releases all secrets in the namespace that have been replicated for the kind of the controller
+// but are not referenced as image pull secret by any object of that kind in the namespace anymore.
+// Replicas that are still referenced by other kinds are kept, see replicator.ReleaseReplica.
+func (c *PullSecretController) deleteUnreferencedReplicas(ctx context.Context, namespace string) error {
+	objects, err := listObjects(ctx, c.client, c.newObject(), client.InNamespace(namespace))
+	if err != nil {
+		return fmt.Errorf("unable to list %ss in namespace %q: %w", c.name, namespace, err)
+	}
+	referenced := sets.NewString()
+	for _, obj := range objects {
+		if !obj.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		referenced.Insert(sourceSecrets(obj)...)
+	}
+	if err := replicator.ReleaseUnreferencedReplicas(ctx, c.client, c.name, namespace, referenced); err != nil {
+		return c.Report(ctx, err)
+	}
+	return nil
+}
+
+// MapSecret maps a secret to all service accounts or pods that reference the secret as source of an image pull secret.
+// Replicas are mapped to the objects that reference their source so that deleted or changed replicas are restored.
+func (c *PullSecretController) MapSecret(obj client.Object) []reconcile.Request {
+	requests := make([]reconcile.Request, 0)
+	for _, key := range replicator.SourceKeys(obj) {
+		objects, err := listObjects(context.Background(), c.client, c.newObject(), client.MatchingFields{replicator.SourceSecretIndex: key})
+		if err != nil {
+			c.log.Error(err, fmt.Sprintf("unable to list %ss for secret", c.name), "secret", key)
+			return nil
+		}
+		for _, o := range objects {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: o.GetName(), Namespace: o.GetNamespace()},
+			})
+		}
+	}
+	return requests
+}
+
+// sourceSecrets returns the keys of all source secrets that are referenced as image pull secrets
+// by the service account or pod in the format <namespace>/<name>.
+// It is also used as index function of the replicator.SourceSecretIndex.
+func sourceSecrets(obj client.Object) []string {
+	return replicator.ReferencedSources(obj, ImagePullSecrets(obj))
+}
+
+// listObjects lists all service accounts or pods depending on the type of the given object.
+func listObjects(ctx context.Context, kubeClient client.Client, kind client.Object, opts ...client.ListOption) ([]client.Object, error) {
+	objects := make([]client.Object, 0)
+	switch kind.(type) {
+	case *corev1.ServiceAccount:
+		list := &corev1.ServiceAccountList{}
+		if err := kubeClient.List(ctx, list, opts...); err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case *corev1.Pod:
+		list := &corev1.PodList{}
+		if err := kubeClient.List(ctx, list, opts...); err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	default:
+		return nil, fmt.Errorf("unsupported type %T", kind)
+	}
+	return objects, nil
+}
+
+// ImagePullSecrets returns the names of all image pull secrets of a service account or pod.
+func ImagePullSecrets(obj client.Object) []string {
+	var refs []corev1.LocalObjectReference
+	switch o := obj.(type) {
+	case *corev1.ServiceAccount:
+		refs = o.ImagePullSecrets
+	case *corev1.Pod:
+		refs = o.Spec.ImagePullSecrets
+	}
+
+	secrets := sets.NewString()
+	for _, ref := range refs {
+		if len(ref.Name) != 0 {
+			secrets.Insert(ref.Name)
+		}
+	}
+	return secrets.List()
+}
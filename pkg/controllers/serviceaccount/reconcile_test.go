@@ -0,0 +1,218 @@
+package serviceaccountctrl_test
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	serviceaccountctrl "github.com/schrodit/secret-replication-controller/pkg/controllers/serviceaccount"
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("controller", func() {
+
+	var (
+		ctrl       reconcile.Reconciler
+		secret     *corev1.Secret
+		namespaces []string
+	)
+
+	BeforeEach(func() {
+		secret = &corev1.Secret{}
+		secret.GenerateName = "e2e-"
+		secret.Namespace = "default"
+		secret.Type = corev1.SecretTypeDockerConfigJson
+		secret.Annotations = map[string]string{
+			v1alpha1.SecretReplicationAllowedNamespacesAnnotation: "e2e-*",
+		}
+		secret.Data = map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte("{}"),
+		}
+
+		Expect(client.Create(context.TODO(), secret)).To(Succeed())
+		namespaces = make([]string, 0)
+
+		ctrl = serviceaccountctrl.NewServiceAccountController(logr.Discard(), client, record.NewFakeRecorder(1024))
+	})
+
+	AfterEach(func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		Expect(client.Delete(ctx, secret)).To(Succeed())
+
+		for _, ns := range namespaces {
+			namespace := &corev1.Namespace{}
+			namespace.Name = ns
+			Expect(client.Delete(ctx, namespace)).To(Succeed())
+		}
+	})
+
+	It("should replicate the image pull secrets of a service account", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+
+		ns := &corev1.Namespace{}
+		ns.GenerateName = "e2e-"
+		Expect(client.Create(ctx, ns)).To(Succeed())
+		namespaces = append(namespaces, ns.Name)
+
+		sa := &corev1.ServiceAccount{}
+		sa.GenerateName = "e2e-"
+		sa.Namespace = ns.Name
+		sa.Annotations = map[string]string{
+			v1alpha1.SecretReplicationFromNamespaceAnnotation: secret.Namespace,
+		}
+		sa.ImagePullSecrets = []corev1.LocalObjectReference{
+			{Name: secret.Name},
+		}
+		Expect(client.Create(ctx, sa)).To(Succeed())
+
+		_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: sa.Name, Namespace: sa.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		newSecret := &corev1.Secret{}
+		Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
+		Expect(newSecret.Type).To(Equal(corev1.SecretTypeDockerConfigJson))
+		Expect(newSecret.Data).To(Equal(secret.Data))
+	})
+
+	It("should delete the replicated image pull secret when the service account is deleted", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+
+		ns := &corev1.Namespace{}
+		ns.GenerateName = "e2e-"
+		Expect(client.Create(ctx, ns)).To(Succeed())
+		namespaces = append(namespaces, ns.Name)
+
+		sa := &corev1.ServiceAccount{}
+		sa.GenerateName = "e2e-"
+		sa.Namespace = ns.Name
+		sa.Annotations = map[string]string{
+			v1alpha1.SecretReplicationFromNamespaceAnnotation: secret.Namespace,
+		}
+		sa.ImagePullSecrets = []corev1.LocalObjectReference{
+			{Name: secret.Name},
+		}
+		Expect(client.Create(ctx, sa)).To(Succeed())
+
+		_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: sa.Name, Namespace: sa.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		newSecret := &corev1.Secret{}
+		Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
+		Expect(replicator.Referrers(newSecret).List()).To(ConsistOf("serviceaccount"))
+
+		Expect(client.Delete(ctx, sa)).To(Succeed())
+		_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: sa.Name, Namespace: sa.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		err = client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should delete the replicated image pull secret when it is removed from the service account", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+
+		ns := &corev1.Namespace{}
+		ns.GenerateName = "e2e-"
+		Expect(client.Create(ctx, ns)).To(Succeed())
+		namespaces = append(namespaces, ns.Name)
+
+		sa := &corev1.ServiceAccount{}
+		sa.GenerateName = "e2e-"
+		sa.Namespace = ns.Name
+		sa.Annotations = map[string]string{
+			v1alpha1.SecretReplicationFromNamespaceAnnotation: secret.Namespace,
+		}
+		sa.ImagePullSecrets = []corev1.LocalObjectReference{
+			{Name: secret.Name},
+		}
+		Expect(client.Create(ctx, sa)).To(Succeed())
+
+		_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: sa.Name, Namespace: sa.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		newSecret := &corev1.Secret{}
+		Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
+
+		sa.ImagePullSecrets = nil
+		Expect(client.Update(ctx, sa)).To(Succeed())
+		_, err = ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: sa.Name, Namespace: sa.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		err = client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should map source secrets and their replicas to the service accounts that reference them", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+
+		ns := &corev1.Namespace{}
+		ns.GenerateName = "e2e-"
+		Expect(client.Create(ctx, ns)).To(Succeed())
+		namespaces = append(namespaces, ns.Name)
+
+		sa := &corev1.ServiceAccount{}
+		sa.GenerateName = "e2e-"
+		sa.Namespace = ns.Name
+		sa.Annotations = map[string]string{
+			v1alpha1.SecretReplicationFromNamespaceAnnotation: secret.Namespace,
+		}
+		sa.ImagePullSecrets = []corev1.LocalObjectReference{
+			{Name: secret.Name},
+		}
+		Expect(client.Create(ctx, sa)).To(Succeed())
+		expectCached(sa)
+
+		_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: sa.Name, Namespace: sa.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+		newSecret := &corev1.Secret{}
+		Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)).To(Succeed())
+
+		mapCtrl := serviceaccountctrl.NewServiceAccountController(logr.Discard(), cachedClient, record.NewFakeRecorder(1024))
+		expected := reconcile.Request{NamespacedName: types.NamespacedName{Name: sa.Name, Namespace: sa.Namespace}}
+		Expect(mapCtrl.MapSecret(secret)).To(ConsistOf(expected))
+		Expect(mapCtrl.MapSecret(newSecret)).To(ConsistOf(expected))
+
+		unrelated := &corev1.Secret{}
+		unrelated.Name = "unrelated"
+		unrelated.Namespace = ns.Name
+		Expect(mapCtrl.MapSecret(unrelated)).To(BeEmpty())
+	})
+
+	It("should not replicate image pull secrets of a service account without the from-namespace annotation", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+
+		ns := &corev1.Namespace{}
+		ns.GenerateName = "e2e-"
+		Expect(client.Create(ctx, ns)).To(Succeed())
+		namespaces = append(namespaces, ns.Name)
+
+		sa := &corev1.ServiceAccount{}
+		sa.GenerateName = "e2e-"
+		sa.Namespace = ns.Name
+		sa.ImagePullSecrets = []corev1.LocalObjectReference{
+			{Name: secret.Name},
+		}
+		Expect(client.Create(ctx, sa)).To(Succeed())
+
+		_, err := ctrl.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: sa.Name, Namespace: sa.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		newSecret := &corev1.Secret{}
+		err = client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, newSecret)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+})
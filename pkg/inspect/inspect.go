@@ -0,0 +1,72 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+)
+
+// Replica describes the state of one replica of a source.
+type Replica struct {
+	// Namespace is the namespace of the replica.
+	Namespace string `json:"namespace"`
+	// Name is the name of the replica.
+	Name string `json:"name"`
+	// State describes whether the replica matches the current data of the source.
+	State replicator.ReplicaState `json:"state"`
+	// LastObservedHash is the hash of the source data the replica has been synced with the last time.
+	LastObservedHash string `json:"lastObservedHash"`
+	// SourceHash is the hash of the current source data.
+	SourceHash string `json:"sourceHash"`
+}
+
+// Inspect returns all secrets that are replicas of the secret with the given key.
+// The replicas are sorted by their namespace.
+func Inspect(ctx context.Context, kubeClient client.Client, key types.NamespacedName) ([]Replica, error) {
+	src := &corev1.Secret{}
+	if err := kubeClient.Get(ctx, key, src); err != nil {
+		return nil, fmt.Errorf("unable to get source secret %s: %w", key.String(), err)
+	}
+
+	replicas, err := replicator.ListReplicas(ctx, kubeClient, src)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list replicas of %s: %w", key.String(), err)
+	}
+
+	res := make([]Replica, len(replicas))
+	for i, replica := range replicas {
+		state, srcHash, err := replicator.StateOf(src, replica)
+		if err != nil {
+			return nil, fmt.Errorf("unable to compare replica %s: %w", replicator.ReplicaOf(replica), err)
+		}
+		res[i] = Replica{
+			Namespace:        replica.GetNamespace(),
+			Name:             replica.GetName(),
+			State:            state,
+			LastObservedHash: replica.GetAnnotations()[v1alpha1.SecretReplicationLastObservedHashAnnotation],
+			SourceHash:       srcHash,
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Namespace < res[j].Namespace
+	})
+	return res, nil
+}
+
+// OutOfSync returns whether at least one of the replicas is not in sync with its source,
+// i.e. it is stale or has drifted.
+func OutOfSync(replicas []Replica) bool {
+	for _, replica := range replicas {
+		if replica.State != replicator.InSync {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package inspect_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/schrodit/secret-replication-controller/pkg/inspect"
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+)
+
+var _ = Describe("inspect", func() {
+
+	var (
+		secret     *corev1.Secret
+		namespaces []*corev1.Namespace
+	)
+
+	BeforeEach(func() {
+		ctx := context.Background()
+		secret = &corev1.Secret{}
+		secret.GenerateName = "e2e-"
+		secret.Namespace = "default"
+		secret.Data = map[string][]byte{
+			"key": []byte("value"),
+		}
+		Expect(client.Create(ctx, secret)).To(Succeed())
+
+		namespaces = make([]*corev1.Namespace, 3)
+		for i := range namespaces {
+			namespaces[i] = &corev1.Namespace{}
+			namespaces[i].GenerateName = "e2e-"
+			Expect(client.Create(ctx, namespaces[i])).To(Succeed())
+
+			_, err := replicator.New(client, secret).ReplicateTo(ctx, namespaces[i].Name)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
+	AfterEach(func() {
+		ctx := context.Background()
+		Expect(client.Delete(ctx, secret)).To(Succeed())
+		for _, ns := range namespaces {
+			Expect(client.Delete(ctx, ns)).To(Succeed())
+		}
+	})
+
+	It("should report in-sync, stale and drifted replicas", func() {
+		ctx := context.Background()
+
+		By("change the data of one replica")
+		replica := &corev1.Secret{}
+		Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: namespaces[1].Name}, replica)).To(Succeed())
+		replica.Data["key"] = []byte("changed")
+		Expect(client.Update(ctx, replica)).To(Succeed())
+
+		replicas, err := inspect.Inspect(ctx, client, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace})
+		Expect(err).ToNot(HaveOccurred())
+		states := map[string]replicator.ReplicaState{}
+		for _, r := range replicas {
+			states[r.Namespace] = r.State
+		}
+		Expect(states).To(Equal(map[string]replicator.ReplicaState{
+			namespaces[0].Name: replicator.InSync,
+			namespaces[1].Name: replicator.Drifted,
+			namespaces[2].Name: replicator.InSync,
+		}))
+		Expect(inspect.OutOfSync(replicas)).To(BeTrue())
+		inSync := make([]inspect.Replica, 0)
+		for _, r := range replicas {
+			if r.State == replicator.InSync {
+				inSync = append(inSync, r)
+			}
+		}
+		Expect(inspect.OutOfSync(inSync)).To(BeFalse())
+
+		By("change the data of the source")
+		secret.Data["key"] = []byte("new")
+		Expect(client.Update(ctx, secret)).To(Succeed())
+		_, err = replicator.New(client, secret).ReplicateTo(ctx, namespaces[0].Name)
+		Expect(err).ToNot(HaveOccurred())
+
+		replicas, err = inspect.Inspect(ctx, client, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(replicas).To(HaveLen(3))
+		Expect(replicas[0].SourceHash).ToNot(BeEmpty())
+		states = map[string]replicator.ReplicaState{}
+		for _, r := range replicas {
+			states[r.Namespace] = r.State
+		}
+		Expect(states).To(Equal(map[string]replicator.ReplicaState{
+			namespaces[0].Name: replicator.InSync,
+			namespaces[1].Name: replicator.Stale,
+			namespaces[2].Name: replicator.Stale,
+		}))
+		Expect(inspect.OutOfSync(replicas)).To(BeTrue())
+	})
+})
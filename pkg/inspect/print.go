@@ -0,0 +1,18 @@
+package inspect
+
+import (
+	"io"
+
+	"github.com/schrodit/secret-replication-controller/pkg/output"
+)
+
+// Print writes the replicas in the given output format, see output.Print.
+func Print(w io.Writer, replicas []Replica, format string) error {
+	return output.Print(w, replicas, format, func(w io.Writer) error {
+		rows := make([][]string, len(replicas))
+		for i, r := range replicas {
+			rows[i] = []string{r.Namespace, r.Name, string(r.State), r.LastObservedHash}
+		}
+		return output.PrintTable(w, []string{"NAMESPACE", "NAME", "STATE", "LAST OBSERVED HASH"}, rows, "No replicas found.")
+	})
+}
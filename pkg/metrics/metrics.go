@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "secret_replication"
+
+var (
+	// Replications counts the replication operations by controller, kind, action and error reason.
+	// Failed replications have the action "Failed" and the reason of the error,
+	// replications that are skipped because of a conflict have the action "Skipped" and the reason "Conflict".
+	Replications = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "replications_total",
+			Help:      "Number of replication operations by controller, kind, action and error reason.",
+		},
+		[]string{"controller", "kind", "action", "reason"},
+	)
+
+	// ManagedReplicas is the number of replicas that are managed for a source.
+	ManagedReplicas = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "managed_replicas",
+			Help:      "Number of replicas that are managed for a source.",
+		},
+		[]string{"controller", "kind", "source_namespace", "source_name"},
+	)
+
+	// ReplicationDuration observes the latency of replications to a single namespace.
+	ReplicationDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "replication_duration_seconds",
+			Help:      "Latency of the replication of a source to a single namespace.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"kind"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(Replications, ManagedReplicas, ReplicationDuration)
+}
@@ -0,0 +1,52 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	// Table prints the objects as human readable table.
+	Table = "table"
+	// JSON prints the objects as json list.
+	JSON = "json"
+)
+
+// Description returns the description of the output flag for the given kind of objects.
+func Description(objects string) string {
+	return fmt.Sprintf("output format of the %s. One of %q or %q", objects, Table, JSON)
+}
+
+// Print writes the objects in the given output format.
+// Tables are written with the given function, see PrintTable.
+func Print(w io.Writer, objects interface{}, format string, printTable func(w io.Writer) error) error {
+	switch format {
+	case Table:
+		return printTable(w)
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(objects)
+	default:
+		return fmt.Errorf("unknown output format %q: expected %q or %q", format, Table, JSON)
+	}
+}
+
+// PrintTable writes the rows as table with the given header.
+// The given message is written instead if there are no rows.
+func PrintTable(w io.Writer, header []string, rows [][]string, empty string) error {
+	if len(rows) == 0 {
+		_, err := fmt.Fprintln(w, empty)
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, row := range append([][]string{header}, rows...) {
+		if _, err := fmt.Fprintln(tw, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,256 @@
+package plan
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1/helper"
+	ingressctrl "github.com/schrodit/secret-replication-controller/pkg/controllers/ingress"
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+)
+
+// Deleted is the action of replicas that would be deleted.
+const Deleted replicator.Action = "Deleted"
+
+// Change describes what would happen to one replica.
+type Change struct {
+	// Kind is the kind of the source and the replica.
+	Kind string `json:"kind"`
+	// Source is the source in the format <namespace>/<name>.
+	Source string `json:"source"`
+	// Referrer is the object that references the source, e.g. an ingress, in the format <kind> <namespace>/<name>.
+	// Empty for sources that are replicated by their own annotations.
+	Referrer string `json:"referrer,omitempty"`
+	// Target is the replica in the format <namespace>/<name>.
+	// Empty if the targets of the source cannot be determined.
+	Target string `json:"target,omitempty"`
+	// Action is the action that would be performed for the replica.
+	Action replicator.Action `json:"action"`
+	// Message describes why the replication would fail or would be skipped.
+	Message string `json:"message,omitempty"`
+}
+
+// Planner evaluates the replication of all annotated sources and ingresses without changing the cluster.
+// The actions are computed from the current state of the replicas with the same checks as the controllers use,
+// so the planner only needs read access to the cluster.
+// Replications to remote clusters and replication policies are not planned.
+type Planner struct {
+	client  client.Client
+	ingress client.Object
+}
+
+// New creates a new planner.
+// Ingresses are evaluated in the api version of the given ingress, they are not evaluated if it is nil.
+func New(kubeClient client.Client, ingress client.Object) *Planner {
+	return &Planner{
+		client:  kubeClient,
+		ingress: ingress,
+	}
+}
+
+// Plan returns all changes that would be performed by the controllers.
+// Replicas that are already up-to-date are omitted.
+func (p *Planner) Plan(ctx context.Context) ([]Change, error) {
+	changes := make([]Change, 0)
+	for _, kind := range []client.Object{&corev1.Secret{}, &corev1.ConfigMap{}} {
+		sources, err := replicator.List(ctx, p.client, kind)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list %ss: %w", replicator.KindName(kind), err)
+		}
+		for _, src := range sources {
+			changes = append(changes, p.planSource(ctx, src)...)
+		}
+	}
+
+	if p.ingress != nil {
+		ingressChanges, err := p.planIngresses(ctx)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, ingressChanges...)
+	}
+	return changes, nil
+}
+
+// planSource evaluates the replication of a source that is configured by its own annotations.
+func (p *Planner) planSource(ctx context.Context, src client.Object) []Change {
+	srcKey, pull, err := replicator.PullSource(src)
+	if err != nil {
+		return []Change{failed(src, "", err)}
+	}
+	if pull {
+		return []Change{p.planPull(ctx, src, srcKey)}
+	}
+
+	r := replicator.New(p.client, src)
+	targets, ok, err := replicator.TargetsFromAnnotations(src)
+	if err != nil {
+		return []Change{failed(src, "", err)}
+	}
+	if !ok {
+		if !controllerutil.ContainsFinalizer(src, v1alpha1.SecretReplicationFinalizer) {
+			return nil
+		}
+		// the source is not replicated anymore so all of its replicas are deleted
+		return p.planDeletion(ctx, r, src, sets.NewString())
+	}
+
+	namespaces, err := targets.Resolve(ctx, p.client, src)
+	if err != nil {
+		return []Change{failed(src, "", err)}
+	}
+
+	changes := make([]Change, 0)
+	for _, namespace := range namespaces.List() {
+		res, err := r.PlanTo(ctx, namespace)
+		res.Err = err
+		target := types.NamespacedName{Name: replicator.TargetName(src), Namespace: namespace}.String()
+		if change, ok := fromResult(src, target, res); ok {
+			changes = append(changes, change)
+		}
+	}
+	return append(changes, p.planDeletion(ctx, r, src, namespaces)...)
+}
+
+// planDeletion returns the replicas of the source that are not in one of the given namespaces.
+func (p *Planner) planDeletion(ctx context.Context, r *replicator.Replicator, src client.Object, namespaces sets.String) []Change {
+	replicas, err := r.StaleReplicas(ctx, namespaces)
+	if err != nil {
+		return []Change{failed(src, "", err)}
+	}
+	changes := make([]Change, 0, len(replicas))
+	for _, replica := range replicas {
+		if !deleted(replica, replicator.AnnotationReferrer) {
+			continue
+		}
+		changes = append(changes, Change{
+			Kind:   replicator.KindName(src),
+			Source: replicator.ReplicaOf(src),
+			Target: replicator.ReplicaOf(replica),
+			Action: Deleted,
+		})
+	}
+	return changes
+}
+
+// planPull evaluates an object that pulls the data of the source with the given key.
+func (p *Planner) planPull(ctx context.Context, dst client.Object, srcKey types.NamespacedName) Change {
+	src, err := replicator.NewObject(dst)
+	if err != nil {
+		return failed(dst, replicator.ReplicaOf(dst), err)
+	}
+	if err := p.client.Get(ctx, srcKey, src); err != nil {
+		change := failed(dst, replicator.ReplicaOf(dst), fmt.Errorf("unable to get source: %w", err))
+		change.Source = srcKey.String()
+		return change
+	}
+	if err := replicator.AuthorizePull(ctx, p.client, src, dst); err != nil {
+		return failed(src, replicator.ReplicaOf(dst), err)
+	}
+	res, err := replicator.New(p.client, src).PlanInto(dst)
+	res.Err = err
+	change, _ := fromResult(src, replicator.ReplicaOf(dst), res)
+	return change
+}
+
+// planIngresses evaluates the replication of the tls secrets of all ingresses
+// as well as the deletion of replicas that are not referenced by ingresses anymore.
+func (p *Planner) planIngresses(ctx context.Context) ([]Change, error) {
+	ingresses, err := ingressctrl.ListIngresses(ctx, p.client, p.ingress)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list ingresses: %w", err)
+	}
+
+	changes := make([]Change, 0)
+	for _, ingress := range ingresses {
+		srcNamespace, ok := helper.GetAnnotation(ingress, v1alpha1.SecretReplicationFromNamespaceAnnotations)
+		if !ok {
+			continue
+		}
+		referrer := fmt.Sprintf("ingress %s", replicator.ReplicaOf(ingress))
+		secretNames := ingressctrl.SecretsFromIngress(ingress)
+		results := replicator.PlanReferencedSecrets(ctx, p.client, ingress, srcNamespace, secretNames)
+		for i, res := range results {
+			change, ok := fromResult(&corev1.Secret{}, types.NamespacedName{Name: secretNames[i], Namespace: ingress.GetNamespace()}.String(), res)
+			if !ok {
+				continue
+			}
+			change.Source = types.NamespacedName{Name: secretNames[i], Namespace: srcNamespace}.String()
+			change.Referrer = referrer
+			changes = append(changes, change)
+		}
+	}
+
+	// replicas of ingresses may also exist in namespaces without any ingresses
+	secrets, err := replicator.List(ctx, p.client, &corev1.Secret{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list secrets: %w", err)
+	}
+	namespaces := sets.NewString()
+	for _, secret := range secrets {
+		if replicator.Referrers(secret).Len() != 0 {
+			namespaces.Insert(secret.GetNamespace())
+		}
+	}
+	for _, namespace := range namespaces.List() {
+		replicas, err := ingressctrl.UnreferencedReplicas(ctx, p.client, p.ingress, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, replica := range replicas {
+			if !deleted(replica, ingressctrl.ReferrerKind) {
+				continue
+			}
+			changes = append(changes, Change{
+				Kind:   replicator.KindName(replica),
+				Source: replica.GetAnnotations()[v1alpha1.SecretReplicationReplicaOfAnnotation],
+				Target: replicator.ReplicaOf(replica),
+				Action: Deleted,
+			})
+		}
+	}
+	return changes, nil
+}
+
+// deleted checks whether the replica would be deleted when it is released by the given referrer kind.
+// Replicas that are still referenced by other kinds are kept, see replicator.ReleaseReplica.
+func deleted(replica client.Object, kind string) bool {
+	return replicator.Referrers(replica).Delete(kind).Len() == 0
+}
+
+// fromResult converts a replication result into a change.
+// Returns false if the replica is already up-to-date.
+func fromResult(src client.Object, target string, res replicator.Result) (Change, bool) {
+	change := Change{
+		Kind:   replicator.KindName(src),
+		Source: replicator.ReplicaOf(src),
+		Target: target,
+		Action: res.Action,
+	}
+	if res.Err != nil {
+		if res.Action != replicator.Skipped {
+			change.Action = replicator.Failed
+		}
+		change.Message = res.Err.Error()
+		return change, true
+	}
+	return change, res.Action != replicator.Unchanged
+}
+
+// failed returns a change for a source whose replication would fail.
+func failed(src client.Object, target string, err error) Change {
+	return Change{
+		Kind:    replicator.KindName(src),
+		Source:  replicator.ReplicaOf(src),
+		Target:  target,
+		Action:  replicator.Failed,
+		Message: err.Error(),
+	}
+}
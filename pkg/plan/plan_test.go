@@ -0,0 +1,170 @@
+package plan_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/output"
+	"github.com/schrodit/secret-replication-controller/pkg/plan"
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+)
+
+var _ = Describe("planner", func() {
+
+	var (
+		secret *corev1.Secret
+		ns     *corev1.Namespace
+	)
+
+	BeforeEach(func() {
+		ctx := context.Background()
+		ns = &corev1.Namespace{}
+		ns.GenerateName = "e2e-"
+		Expect(client.Create(ctx, ns)).To(Succeed())
+
+		secret = &corev1.Secret{}
+		secret.GenerateName = "e2e-"
+		secret.Namespace = "default"
+		secret.Annotations = map[string]string{
+			v1alpha1.SecretReplicationNamespacesAnnotation: ns.Name,
+		}
+		secret.Data = map[string][]byte{
+			"key": []byte("value"),
+		}
+		Expect(client.Create(ctx, secret)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		ctx := context.Background()
+		Expect(client.Delete(ctx, secret)).To(Succeed())
+		Expect(client.Delete(ctx, ns)).To(Succeed())
+	})
+
+	It("should plan the creation of a replica without creating it", func() {
+		ctx := context.Background()
+
+		changes, err := plan.New(client, nil).Plan(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changes).To(ContainElement(plan.Change{
+			Kind:   "secret",
+			Source: replicator.ReplicaOf(secret),
+			Target: types.NamespacedName{Name: secret.Name, Namespace: ns.Name}.String(),
+			Action: replicator.Created,
+		}))
+
+		err = client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, &corev1.Secret{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should plan the recreation of a replica whose type differs as update", func() {
+		ctx := context.Background()
+
+		replica := &corev1.Secret{}
+		replica.Name = secret.Name
+		replica.Namespace = ns.Name
+		replica.Type = "custom"
+		replica.Annotations = map[string]string{
+			v1alpha1.SecretReplicationReplicaOfAnnotation: replicator.ReplicaOf(secret),
+		}
+		Expect(client.Create(ctx, replica)).To(Succeed())
+
+		changes, err := plan.New(client, nil).Plan(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changes).To(ContainElement(plan.Change{
+			Kind:   "secret",
+			Source: replicator.ReplicaOf(secret),
+			Target: types.NamespacedName{Name: secret.Name, Namespace: ns.Name}.String(),
+			Action: replicator.Updated,
+		}))
+
+		Expect(client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: ns.Name}, replica)).To(Succeed())
+		Expect(replica.Type).To(Equal(corev1.SecretType("custom")))
+	})
+
+	It("should not plan the deletion of a replica that is referenced by another kind", func() {
+		ctx := context.Background()
+
+		replica := &corev1.Secret{}
+		replica.Name = "stale"
+		replica.Namespace = ns.Name
+		replica.Annotations = map[string]string{
+			v1alpha1.SecretReplicationReplicaOfAnnotation:                          replicator.ReplicaOf(secret),
+			v1alpha1.SecretReplicationReferencedByAnnotationPrefix + "annotations": "true",
+			v1alpha1.SecretReplicationReferencedByAnnotationPrefix + "ingress":     "true",
+		}
+		Expect(client.Create(ctx, replica)).To(Succeed())
+
+		changes, err := plan.New(client, nil).Plan(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		for _, change := range changes {
+			Expect(change.Target).ToNot(Equal(replicator.ReplicaOf(replica)))
+		}
+	})
+
+	It("should not plan an update of a pulling object that is up-to-date", func() {
+		ctx := context.Background()
+
+		src := &corev1.Secret{}
+		src.GenerateName = "e2e-"
+		src.Namespace = "default"
+		src.Annotations = map[string]string{
+			v1alpha1.SecretReplicationAllowedNamespacesAnnotation: ns.Name,
+		}
+		src.Data = map[string][]byte{
+			"key": []byte("value"),
+		}
+		Expect(client.Create(ctx, src)).To(Succeed())
+		defer func() {
+			Expect(client.Delete(ctx, src)).To(Succeed())
+		}()
+
+		dst := &corev1.Secret{}
+		dst.Name = "pull"
+		dst.Namespace = ns.Name
+		dst.Annotations = map[string]string{
+			v1alpha1.SecretReplicationReplicateFromAnnotation: replicator.ReplicaOf(src),
+		}
+		// keys of the pulling object that are not part of the source must not be taken into account
+		dst.Data = map[string][]byte{
+			"local": []byte("value"),
+		}
+		Expect(client.Create(ctx, dst)).To(Succeed())
+		_, err := replicator.New(client, src).ReplicateInto(ctx, dst)
+		Expect(err).ToNot(HaveOccurred())
+
+		changes, err := plan.New(client, nil).Plan(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		for _, change := range changes {
+			Expect(change.Target).ToNot(Equal(replicator.ReplicaOf(dst)))
+		}
+	})
+
+	It("should print the changes as json", func() {
+		changes := []plan.Change{
+			{
+				Kind:   "secret",
+				Source: "default/my-secret",
+				Target: "other/my-secret",
+				Action: plan.Deleted,
+			},
+		}
+		buf := &bytes.Buffer{}
+		Expect(plan.Print(buf, changes, output.JSON)).To(Succeed())
+
+		printed := make([]plan.Change, 0)
+		Expect(json.Unmarshal(buf.Bytes(), &printed)).To(Succeed())
+		Expect(printed).To(Equal(changes))
+	})
+
+	It("should fail for an unknown output format", func() {
+		Expect(plan.Print(&bytes.Buffer{}, nil, "yaml")).ToNot(Succeed())
+	})
+})
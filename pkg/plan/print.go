@@ -0,0 +1,25 @@
+package plan
+
+import (
+	"io"
+
+	"github.com/schrodit/secret-replication-controller/pkg/output"
+)
+
+// Print writes the changes in the given output format, see output.Print.
+func Print(w io.Writer, changes []Change, format string) error {
+	return output.Print(w, changes, format, func(w io.Writer) error {
+		rows := make([][]string, len(changes))
+		for i, c := range changes {
+			rows[i] = []string{c.Kind, c.Source, orNone(c.Referrer), orNone(c.Target), string(c.Action), c.Message}
+		}
+		return output.PrintTable(w, []string{"KIND", "SOURCE", "REFERRER", "TARGET", "ACTION", "MESSAGE"}, rows, "No changes.")
+	})
+}
+
+func orNone(val string) string {
+	if len(val) == 0 {
+		return "<none>"
+	}
+	return val
+}
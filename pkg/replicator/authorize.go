@@ -0,0 +1,79 @@
+package replicator
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1/helper"
+	"github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+)
+
+// AuthorizePull checks whether the source allows to be pulled into the namespace of the requester.
+// The allowed namespaces are defined by the allowed-namespaces and allowed-namespace-selector annotations
+// either on the source itself or on the namespace of the source.
+// Denied requests result in a Forbidden error whose source is the requester so that it is reported on the requesting object.
+func AuthorizePull(ctx context.Context, kubeClient client.Client, src client.Object, requester client.Object) error {
+	forbidden := func(msg string, err error) error {
+		return errors.Error{
+			Src:    requester,
+			Reason: errors.Forbidden,
+			Msg:    msg,
+			Err:    err,
+		}
+	}
+
+	requesterNamespace := &corev1.Namespace{}
+	if err := kubeClient.Get(ctx, client.ObjectKey{Name: requester.GetNamespace()}, requesterNamespace); err != nil {
+		return fmt.Errorf("unable to get namespace %s: %w", requester.GetNamespace(), err)
+	}
+
+	allowed, err := allowsNamespace(src, requesterNamespace)
+	if err != nil {
+		return forbidden(fmt.Sprintf("unable to parse the allowed namespaces of %s %s", KindName(src), ReplicaOf(src)), err)
+	}
+	if allowed {
+		return nil
+	}
+
+	srcNamespace := &corev1.Namespace{}
+	if err := kubeClient.Get(ctx, client.ObjectKey{Name: src.GetNamespace()}, srcNamespace); err != nil {
+		return fmt.Errorf("unable to get namespace %s: %w", src.GetNamespace(), err)
+	}
+	allowed, err = allowsNamespace(srcNamespace, requesterNamespace)
+	if err != nil {
+		return forbidden(fmt.Sprintf("unable to parse the allowed namespaces of namespace %s", src.GetNamespace()), err)
+	}
+	if allowed {
+		return nil
+	}
+
+	return forbidden(fmt.Sprintf("namespace %s is not allowed to replicate %s %s", requester.GetNamespace(), KindName(src), ReplicaOf(src)), nil)
+}
+
+// allowsNamespace checks whether the allow-list annotations of the given object match the namespace.
+func allowsNamespace(obj client.Object, namespace *corev1.Namespace) (bool, error) {
+	if val, ok := helper.GetAnnotation(obj, v1alpha1.SecretReplicationAllowedNamespacesAnnotations); ok {
+		matcher, err := helper.ParseNamespaceList(val)
+		if err != nil {
+			return false, err
+		}
+		if matcher.Matches(namespace.Name) {
+			return true, nil
+		}
+	}
+	if val, ok := helper.GetAnnotation(obj, v1alpha1.SecretReplicationAllowedNamespaceSelectorAnnotations); ok {
+		selector, err := labels.Parse(val)
+		if err != nil {
+			return false, err
+		}
+		if selector.Matches(labels.Set(namespace.Labels)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
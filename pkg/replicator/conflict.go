@@ -0,0 +1,82 @@
+package replicator
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1/helper"
+	"github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+)
+
+// ConflictPolicy defines how objects in target namespaces are handled that have the name of the replica
+// but are not replicas of the source.
+type ConflictPolicy string
+
+const (
+	// ConflictPolicySkip leaves conflicting objects untouched and reports the conflict.
+	ConflictPolicySkip ConflictPolicy = "skip"
+	// ConflictPolicyAdopt takes ownership of conflicting objects that are not replicas of any source.
+	// Replicas of other sources are still reported as conflict.
+	ConflictPolicyAdopt ConflictPolicy = "adopt"
+	// ConflictPolicyOverwrite takes ownership of all conflicting objects including replicas of other sources.
+	ConflictPolicyOverwrite ConflictPolicy = "overwrite"
+)
+
+// ConflictPolicyFromAnnotations returns the conflict policy of the source.
+// Defaults to ConflictPolicySkip if the source does not define a policy.
+func ConflictPolicyFromAnnotations(src client.Object) (ConflictPolicy, error) {
+	val, ok := helper.GetAnnotation(src, v1alpha1.SecretReplicationConflictPolicyAnnotations)
+	if !ok {
+		return ConflictPolicySkip, nil
+	}
+	policy := ConflictPolicy(strings.TrimSpace(val))
+	switch policy {
+	case ConflictPolicySkip, ConflictPolicyAdopt, ConflictPolicyOverwrite:
+		return policy, nil
+	default:
+		return "", errors.Error{
+			Src:    src,
+			Reason: errors.InvalidConflictPolicy,
+			Msg: fmt.Sprintf("invalid conflict policy %q: expected one of %q, %q or %q",
+				val, ConflictPolicySkip, ConflictPolicyAdopt, ConflictPolicyOverwrite),
+		}
+	}
+}
+
+// checkConflict returns a conflict error if the destination is not a replica of the source
+// and the conflict policy does not allow to take ownership of it.
+func checkConflict(src, dst client.Object, policy ConflictPolicy) error {
+	if canTakeOwnership(src, dst, policy) {
+		return nil
+	}
+	owner := "it is not a replica"
+	if replicaOf, ok := dst.GetAnnotations()[v1alpha1.SecretReplicationReplicaOfAnnotation]; ok {
+		owner = fmt.Sprintf("it is a replica of %s", replicaOf)
+	}
+	return errors.Error{
+		Src:    src,
+		Dst:    dst,
+		Reason: errors.Conflict,
+		Msg: fmt.Sprintf("%s %s already exists and %s. Set the conflict policy to take ownership of it",
+			KindName(dst), ReplicaOf(dst), owner),
+	}
+}
+
+// canTakeOwnership checks whether the destination is a replica of the source
+// or the conflict policy allows to take ownership of it.
+func canTakeOwnership(src, dst client.Object, policy ConflictPolicy) bool {
+	replicaOf, ok := dst.GetAnnotations()[v1alpha1.SecretReplicationReplicaOfAnnotation]
+	switch {
+	case ok && replicaOf == ReplicaOf(src):
+		return true
+	case policy == ConflictPolicyOverwrite:
+		return true
+	case policy == ConflictPolicyAdopt && !ok:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,92 @@
+package replicator
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+)
+
+// DefaultPreservedAnnotations are the annotations of source objects that are copied to all replicas.
+// Entries ending with "*" match all annotations with the given prefix.
+// The list can be extended per source object with the preserve-annotations annotation.
+var DefaultPreservedAnnotations = sets.NewString()
+
+// metadata is the part of the source metadata that is replicated.
+type metadata struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// replicatedMetadata returns the labels and allow-listed annotations of the source that are copied to replicas.
+func replicatedMetadata(src client.Object) metadata {
+	allowed := sets.NewString(DefaultPreservedAnnotations.UnsortedList()...)
+	if val, ok := v1alpha1.SecretReplicationPreserveAnnotationsAnnotations.Get(src.GetAnnotations()); ok {
+		for _, key := range strings.Split(val, ",") {
+			if key = strings.TrimSpace(key); len(key) != 0 {
+				allowed.Insert(key)
+			}
+		}
+	}
+
+	md := metadata{}
+	if len(src.GetLabels()) != 0 {
+		md.Labels = make(map[string]string, len(src.GetLabels()))
+		for key, val := range src.GetLabels() {
+			md.Labels[key] = val
+		}
+	}
+	for key, val := range src.GetAnnotations() {
+		if isReplicationAnnotation(key) || !annotationAllowed(allowed, key) {
+			continue
+		}
+		if md.Annotations == nil {
+			md.Annotations = map[string]string{}
+		}
+		md.Annotations[key] = val
+	}
+	return md
+}
+
+// applyMetadata sets the replicated metadata of the source on the replica.
+// Labels and annotations of the replica are replaced so that removed entries are also removed from the replica.
+func applyMetadata(src, replica client.Object) {
+	md := replicatedMetadata(src)
+	replica.SetLabels(md.Labels)
+	annotations := map[string]string{}
+	for key, val := range md.Annotations {
+		annotations[key] = val
+	}
+	replica.SetAnnotations(annotations)
+}
+
+// annotationAllowed checks whether the annotation key is matched by one of the allowed keys or prefixes.
+func annotationAllowed(allowed sets.String, key string) bool {
+	if allowed.Has(key) {
+		return true
+	}
+	for _, entry := range allowed.UnsortedList() {
+		if strings.HasSuffix(entry, "*") && strings.HasPrefix(key, strings.TrimSuffix(entry, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// isReplicationAnnotation checks whether the annotation is used to configure the replication itself.
+// These annotations are never copied as they would cause replicas to be replicated again.
+func isReplicationAnnotation(key string) bool {
+	if strings.HasPrefix(key, v1alpha1.DefaultAnnotationPrefix+v1alpha1.Separator) {
+		return true
+	}
+	for _, set := range v1alpha1.UserFacingAnnotationSets() {
+		for _, ann := range set.List() {
+			if ann == key {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package replicator
+
+import (
+	goerrors "errors"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+	"github.com/schrodit/secret-replication-controller/pkg/metrics"
+)
+
+// Failed is the action that is used in metrics for failed replications.
+const Failed Action = "Failed"
+
+// RecordResults records the outcome of replications of the given controller in the replication metrics.
+func RecordResults(controller string, src client.Object, results []Result) {
+	kind := KindName(src)
+	for _, res := range results {
+		action, reason := res.Action, ""
+		if res.Err != nil {
+			reason = string(errors.InternalError)
+			var intErr errors.Error
+			if goerrors.As(res.Err, &intErr) {
+				reason = string(intErr.Reason)
+			}
+			// conflicts are returned as skipped results with the conflict as error
+			if res.Action != Skipped {
+				action = Failed
+			}
+		}
+		metrics.Replications.WithLabelValues(controller, kind, string(action), reason).Inc()
+	}
+}
+
+// RecordManagedReplicas records the number of replicas that are in sync with the source.
+// The source is identified by the given owner, e.g. the source itself or a replication policy.
+func RecordManagedReplicas(controller string, src client.Object, owner client.Object, results []Result) {
+	managed := 0
+	for _, res := range results {
+		if res.Err == nil && res.Action != Skipped {
+			managed++
+		}
+	}
+	metrics.ManagedReplicas.WithLabelValues(controller, KindName(src), owner.GetNamespace(), owner.GetName()).Set(float64(managed))
+}
+
+// ForgetManagedReplicas removes the managed replicas metric of a source that is not replicated anymore.
+func ForgetManagedReplicas(controller string, src client.Object, owner client.Object) {
+	metrics.ManagedReplicas.DeleteLabelValues(controller, KindName(src), owner.GetNamespace(), owner.GetName())
+}
@@ -0,0 +1,238 @@
+package replicator
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// This file contains the kind specific functions for all kinds that can be replicated.
+
+// NewObject returns a new empty object of the same kind as the given object.
+func NewObject(obj client.Object) (client.Object, error) {
+	switch obj.(type) {
+	case *corev1.Secret:
+		return &corev1.Secret{}, nil
+	case *corev1.ConfigMap:
+		return &corev1.ConfigMap{}, nil
+	default:
+		return nil, fmt.Errorf("replication of %T is not supported", obj)
+	}
+}
+
+// newApplyObject returns a new empty object of the same kind as the given object with the given key
+// that can be used for server-side apply requests.
+// In contrast to NewObject the type meta is set as it is required by apply requests.
+func newApplyObject(kubeClient client.Client, obj client.Object, key types.NamespacedName) (client.Object, error) {
+	applyObj, err := NewObject(obj)
+	if err != nil {
+		return nil, err
+	}
+	gvk, err := apiutil.GVKForObject(applyObj, kubeClient.Scheme())
+	if err != nil {
+		return nil, err
+	}
+	applyObj.GetObjectKind().SetGroupVersionKind(gvk)
+	applyObj.SetName(key.Name)
+	applyObj.SetNamespace(key.Namespace)
+	return applyObj, nil
+}
+
+// List lists all objects of the same kind as the given object.
+func List(ctx context.Context, kubeClient client.Client, kind client.Object, opts ...client.ListOption) ([]client.Object, error) {
+	var (
+		list  client.ObjectList
+		items func() []client.Object
+	)
+	switch kind.(type) {
+	case *corev1.Secret:
+		secretList := &corev1.SecretList{}
+		list = secretList
+		items = func() []client.Object {
+			objects := make([]client.Object, len(secretList.Items))
+			for i := range secretList.Items {
+				objects[i] = &secretList.Items[i]
+			}
+			return objects
+		}
+	case *corev1.ConfigMap:
+		configMapList := &corev1.ConfigMapList{}
+		list = configMapList
+		items = func() []client.Object {
+			objects := make([]client.Object, len(configMapList.Items))
+			for i := range configMapList.Items {
+				objects[i] = &configMapList.Items[i]
+			}
+			return objects
+		}
+	default:
+		return nil, fmt.Errorf("replication of %T is not supported", kind)
+	}
+
+	if err := kubeClient.List(ctx, list, opts...); err != nil {
+		return nil, err
+	}
+	return items(), nil
+}
+
+// copyData copies the replicated data of the source to the destination object.
+func copyData(src, dst client.Object) {
+	switch s := src.(type) {
+	case *corev1.Secret:
+		dst.(*corev1.Secret).Data = s.Data
+	case *corev1.ConfigMap:
+		d := dst.(*corev1.ConfigMap)
+		d.Data = s.Data
+		d.BinaryData = s.BinaryData
+	}
+}
+
+// copyType copies the type of a source secret to the destination.
+// The type of a secret is immutable so it has to match the type of an existing destination.
+func copyType(src, dst client.Object) {
+	if s, ok := src.(*corev1.Secret); ok {
+		dst.(*corev1.Secret).Type = s.Type
+	}
+}
+
+// typeChanged checks whether the immutable type of the destination differs from the source.
+func typeChanged(src, dst client.Object) bool {
+	s, ok := src.(*corev1.Secret)
+	if !ok {
+		return false
+	}
+	d, ok := dst.(*corev1.Secret)
+	if !ok {
+		return false
+	}
+	// the api server defaults empty types to opaque
+	return secretType(s) != secretType(d)
+}
+
+func secretType(secret *corev1.Secret) corev1.SecretType {
+	if len(secret.Type) == 0 {
+		return corev1.SecretTypeOpaque
+	}
+	return secret.Type
+}
+
+// dataHash creates a hash value of the replicated data and metadata of the given object.
+// Changing the hashed fields makes the hashes of all existing replicas outdated so that they are rewritten once,
+// see the upgrade notes in the README.
+func dataHash(obj client.Object) (string, error) {
+	var hashable interface{}
+	switch o := obj.(type) {
+	case *corev1.Secret:
+		hashable = struct {
+			Type corev1.SecretType `json:"type"`
+			Data map[string][]byte `json:"data,omitempty"`
+			metadata
+		}{
+			Type:     secretType(o),
+			Data:     o.Data,
+			metadata: replicatedMetadata(o),
+		}
+	case *corev1.ConfigMap:
+		hashable = struct {
+			Data       map[string]string `json:"data,omitempty"`
+			BinaryData map[string][]byte `json:"binaryData,omitempty"`
+			metadata
+		}{
+			Data:       o.Data,
+			BinaryData: o.BinaryData,
+			metadata:   replicatedMetadata(o),
+		}
+	default:
+		return "", fmt.Errorf("replication of %T is not supported", obj)
+	}
+
+	// create a hashable representation of the data using json
+	data, err := json.Marshal(hashable)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha1.New()
+	_, _ = h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// contentHash creates a hash value of the data of the given object.
+// In contrast to dataHash the metadata is not included so that the hash of a source and its replicas can be compared.
+func contentHash(obj client.Object) (string, error) {
+	var hashable interface{}
+	switch o := obj.(type) {
+	case *corev1.Secret:
+		hashable = struct {
+			Data map[string][]byte `json:"data,omitempty"`
+		}{
+			Data: o.Data,
+		}
+	case *corev1.ConfigMap:
+		hashable = struct {
+			Data       map[string]string `json:"data,omitempty"`
+			BinaryData map[string][]byte `json:"binaryData,omitempty"`
+		}{
+			Data:       o.Data,
+			BinaryData: o.BinaryData,
+		}
+	default:
+		return "", fmt.Errorf("replication of %T is not supported", obj)
+	}
+
+	data, err := json.Marshal(hashable)
+	if err != nil {
+		return "", err
+	}
+	h := sha1.New()
+	_, _ = h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// pruneData removes all data keys from the destination that are not part of the source.
+// Returns whether a key has been removed.
+func pruneData(src, dst client.Object) bool {
+	pruned := false
+	switch s := src.(type) {
+	case *corev1.Secret:
+		d := dst.(*corev1.Secret)
+		for key := range d.Data {
+			if _, ok := s.Data[key]; !ok {
+				delete(d.Data, key)
+				pruned = true
+			}
+		}
+	case *corev1.ConfigMap:
+		d := dst.(*corev1.ConfigMap)
+		for key := range d.Data {
+			if _, ok := s.Data[key]; !ok {
+				delete(d.Data, key)
+				pruned = true
+			}
+		}
+		for key := range d.BinaryData {
+			if _, ok := s.BinaryData[key]; !ok {
+				delete(d.BinaryData, key)
+				pruned = true
+			}
+		}
+	}
+	return pruned
+}
+
+// KindName returns a human readable name of the kind of the object.
+func KindName(obj client.Object) string {
+	switch obj.(type) {
+	case *corev1.ConfigMap:
+		return "configmap"
+	default:
+		return "secret"
+	}
+}
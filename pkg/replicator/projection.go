@@ -0,0 +1,169 @@
+package replicator
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+)
+
+// projection defines which keys of the source data are copied to the replicas and how they are named.
+type projection struct {
+	include sets.String
+	exclude sets.String
+	mapping map[string]string
+}
+
+// TargetName returns the name of the replicas of the given source.
+// The name defaults to the name of the source and can be overwritten with the target-name annotation.
+func TargetName(src client.Object) string {
+	if name, ok := v1alpha1.SecretReplicationTargetNameAnnotations.Get(src.GetAnnotations()); ok && len(strings.TrimSpace(name)) != 0 {
+		return strings.TrimSpace(name)
+	}
+	return src.GetName()
+}
+
+// projectionFromAnnotations parses the key include, exclude and mapping annotations of the source.
+func projectionFromAnnotations(src client.Object) (*projection, error) {
+	p := &projection{
+		mapping: map[string]string{},
+	}
+	if val, ok := v1alpha1.SecretReplicationIncludeKeysAnnotations.Get(src.GetAnnotations()); ok {
+		p.include = parseKeyList(val)
+	}
+	if val, ok := v1alpha1.SecretReplicationExcludeKeysAnnotations.Get(src.GetAnnotations()); ok {
+		p.exclude = parseKeyList(val)
+	}
+	if val, ok := v1alpha1.SecretReplicationKeyMappingAnnotations.Get(src.GetAnnotations()); ok {
+		targets := sets.NewString()
+		for _, entry := range strings.Split(val, ",") {
+			entry = strings.TrimSpace(entry)
+			if len(entry) == 0 {
+				continue
+			}
+			parts := strings.SplitN(entry, "=", 2)
+			if len(parts) != 2 || len(strings.TrimSpace(parts[0])) == 0 || len(strings.TrimSpace(parts[1])) == 0 {
+				return nil, errors.Error{
+					Src:    src,
+					Reason: errors.InvalidKeyMapping,
+					Msg:    fmt.Sprintf("invalid key mapping %q: expected the format <source key>=<target key>", entry),
+				}
+			}
+			from, to := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+			if targets.Has(to) {
+				return nil, errors.Error{
+					Src:    src,
+					Reason: errors.InvalidKeyMapping,
+					Msg:    fmt.Sprintf("invalid key mapping: multiple keys are mapped to %q", to),
+				}
+			}
+			targets.Insert(to)
+			p.mapping[from] = to
+		}
+	}
+	return p, nil
+}
+
+// key returns the target key of the given source key and whether the key should be copied.
+func (p *projection) key(key string) (string, bool) {
+	if p.include != nil && !p.include.Has(key) {
+		return "", false
+	}
+	if p.exclude != nil && p.exclude.Has(key) {
+		return "", false
+	}
+	if to, ok := p.mapping[key]; ok {
+		return to, true
+	}
+	return key, true
+}
+
+// project returns a copy of the source that only contains the projected data.
+func project(src client.Object) (client.Object, error) {
+	p, err := projectionFromAnnotations(src)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := sets.NewString()
+	checkKey := func(key string) error {
+		if keys.Has(key) {
+			return errors.Error{
+				Src:    src,
+				Reason: errors.InvalidKeyMapping,
+				Msg:    fmt.Sprintf("invalid key mapping: key %q is defined multiple times", key),
+			}
+		}
+		keys.Insert(key)
+		return nil
+	}
+
+	switch s := src.(type) {
+	case *corev1.Secret:
+		projected := s.DeepCopy()
+		projected.Data = nil
+		for key, val := range s.Data {
+			to, ok := p.key(key)
+			if !ok {
+				continue
+			}
+			if err := checkKey(to); err != nil {
+				return nil, err
+			}
+			if projected.Data == nil {
+				projected.Data = map[string][]byte{}
+			}
+			projected.Data[to] = val
+		}
+		return projected, nil
+	case *corev1.ConfigMap:
+		projected := s.DeepCopy()
+		projected.Data = nil
+		projected.BinaryData = nil
+		for key, val := range s.Data {
+			to, ok := p.key(key)
+			if !ok {
+				continue
+			}
+			if err := checkKey(to); err != nil {
+				return nil, err
+			}
+			if projected.Data == nil {
+				projected.Data = map[string]string{}
+			}
+			projected.Data[to] = val
+		}
+		for key, val := range s.BinaryData {
+			to, ok := p.key(key)
+			if !ok {
+				continue
+			}
+			if err := checkKey(to); err != nil {
+				return nil, err
+			}
+			if projected.BinaryData == nil {
+				projected.BinaryData = map[string][]byte{}
+			}
+			projected.BinaryData[to] = val
+		}
+		return projected, nil
+	default:
+		return nil, fmt.Errorf("replication of %T is not supported", src)
+	}
+}
+
+// parseKeyList parses a comma separated list of data keys.
+func parseKeyList(val string) sets.String {
+	keys := sets.NewString()
+	for _, key := range strings.Split(val, ",") {
+		if key = strings.TrimSpace(key); len(key) != 0 {
+			keys.Insert(key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,134 @@
+package replicator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+)
+
+// PullSource returns the key of the source that should be pulled into the given object.
+// Returns false if the object does not define a source.
+func PullSource(obj client.Object) (types.NamespacedName, bool, error) {
+	val, ok := v1alpha1.SecretReplicationReplicateFromAnnotations.Get(obj.GetAnnotations())
+	if !ok {
+		return types.NamespacedName{}, false, nil
+	}
+	parts := strings.Split(strings.TrimSpace(val), "/")
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return types.NamespacedName{}, true, errors.Error{
+			Src:    obj,
+			Reason: errors.InvalidSource,
+			Msg:    fmt.Sprintf("invalid source %q: expected the format <namespace>/<name>", val),
+		}
+	}
+	return types.NamespacedName{Namespace: parts[0], Name: parts[1]}, true, nil
+}
+
+// ReplicateInto copies the projected data of the source into the given existing object.
+// In contrast to ReplicateTo the metadata and the type of the destination are not touched
+// as the destination is owned by the user that requested the replication.
+// Data keys of the destination that are not part of the source are removed
+// so that the destination contains an exact copy of the source data.
+func (r *Replicator) ReplicateInto(ctx context.Context, dst client.Object) (Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+	kind := KindName(r.src)
+	res := Result{
+		Namespace: dst.GetNamespace(),
+	}
+
+	projected, err := project(r.src)
+	if err != nil {
+		return res, err
+	}
+	srcHash, err := dataHash(projected)
+	if err != nil {
+		return res, fmt.Errorf("unable to hash data of source %s: %w", kind, err)
+	}
+	res.Hash = srcHash
+	if pulled(dst, srcHash) {
+		res.Action = Unchanged
+		return res, nil
+	}
+
+	log.V(3).Info("Pulled resource out-of-date. Updating...")
+	obj, err := newApplyObject(r.client, dst, types.NamespacedName{Name: dst.GetName(), Namespace: dst.GetNamespace()})
+	if err != nil {
+		return res, err
+	}
+	copyData(projected, obj)
+	setAnnotation(obj, v1alpha1.SecretReplicationLastObservedHashAnnotation, srcHash)
+	if err := r.pull(ctx, projected, obj); err != nil {
+		return res, errors.Error{
+			Src:    dst,
+			Reason: errors.UpdateError,
+			Msg:    fmt.Sprintf("unable to update %s %s with the data of %s", kind, ReplicaOf(dst), ReplicaOf(r.src)),
+			Err:    err,
+		}
+	}
+	res.Action = Updated
+	return res, nil
+}
+
+// pull applies the projected data to the given object and removes the keys
+// that the user had set on the object but that are not part of the source.
+func (r *Replicator) pull(ctx context.Context, projected, obj client.Object) error {
+	if err := r.client.Patch(ctx, obj, client.Apply, client.FieldOwner(FieldManager), client.ForceOwnership); err != nil {
+		return err
+	}
+
+	applied := obj.DeepCopyObject().(client.Object)
+	if !pruneData(projected, obj) {
+		return nil
+	}
+	return r.client.Patch(ctx, obj, client.MergeFromWithOptions(applied, client.MergeFromWithOptimisticLock{}))
+}
+
+// PlanInto returns the result that ReplicateInto would have for the given object without changing the cluster.
+func (r *Replicator) PlanInto(dst client.Object) (Result, error) {
+	res := Result{
+		Namespace: dst.GetNamespace(),
+	}
+	projected, err := project(r.src)
+	if err != nil {
+		return res, err
+	}
+	srcHash, err := dataHash(projected)
+	if err != nil {
+		return res, fmt.Errorf("unable to hash data of source %s: %w", KindName(r.src), err)
+	}
+	res.Hash = srcHash
+	res.Action = Updated
+	if pulled(dst, srcHash) {
+		res.Action = Unchanged
+	}
+	return res, nil
+}
+
+// pulled checks whether the data with the given hash has already been pulled into the object.
+func pulled(dst client.Object, srcHash string) bool {
+	return dst.GetAnnotations()[v1alpha1.SecretReplicationLastObservedHashAnnotation] == srcHash
+}
+
+// Inject copies the projected data of the source into the given object without writing it to the cluster,
+// e.g. to fill pulling objects at admission time before they are created.
+// The last observed hash is set so that the object is not updated again by ReplicateInto.
+func (r *Replicator) Inject(dst client.Object) error {
+	projected, err := project(r.src)
+	if err != nil {
+		return err
+	}
+	srcHash, err := dataHash(projected)
+	if err != nil {
+		return fmt.Errorf("unable to hash data of source %s: %w", KindName(r.src), err)
+	}
+	copyData(projected, dst)
+	setAnnotation(dst, v1alpha1.SecretReplicationLastObservedHashAnnotation, srcHash)
+	return nil
+}
@@ -0,0 +1,205 @@
+package replicator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1/helper"
+	"github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+)
+
+// AnnotationReferrer is the referrer kind of replicas that are replicated because of the replication annotations of their source.
+const AnnotationReferrer = "annotations"
+
+// SourceSecretIndex is the name of the field index that maps source secrets in the format <namespace>/<name>
+// to the referrers, e.g. ingresses, that reference them, see ReferencedSources.
+const SourceSecretIndex = "replication.schrodit.tech/sourceSecrets"
+
+// ReplicateReferencedSecrets replicates the secrets with the given names from the source namespace
+// into the namespace of the referrer, e.g. an ingress that references tls secrets.
+// The sources have to allow the namespace of the referrer, see AuthorizePull.
+// The replicas are marked with the kind of the referrer, see ReferrerKind.
+// The results are returned in the order of the secret names, the results of failed replications contain the error.
+func ReplicateReferencedSecrets(ctx context.Context, kubeClient client.Client, referrer client.Object, srcNamespace string, secretNames []string) []Result {
+	return referencedSecrets(ctx, kubeClient, referrer, srcNamespace, secretNames, (*Replicator).ReplicateTo)
+}
+
+// PlanReferencedSecrets returns the results that ReplicateReferencedSecrets would have without changing the cluster.
+func PlanReferencedSecrets(ctx context.Context, kubeClient client.Client, referrer client.Object, srcNamespace string, secretNames []string) []Result {
+	return referencedSecrets(ctx, kubeClient, referrer, srcNamespace, secretNames, (*Replicator).PlanTo)
+}
+
+// referencedSecrets calls the given replicate function for the replicators of all referenced secrets.
+func referencedSecrets(ctx context.Context, kubeClient client.Client, referrer client.Object, srcNamespace string, secretNames []string,
+	replicate func(r *Replicator, ctx context.Context, namespace string) (Result, error)) []Result {
+	results := make([]Result, len(secretNames))
+	kind, err := ReferrerKind(kubeClient, referrer)
+	if err != nil {
+		for i := range results {
+			results[i] = Result{
+				Namespace: referrer.GetNamespace(),
+				Err:       fmt.Errorf("unable to determine kind of %s: %w", ReplicaOf(referrer), err),
+			}
+		}
+		return results
+	}
+	for i, secretName := range secretNames {
+		results[i] = referencedSecret(ctx, kubeClient, referrer, kind, client.ObjectKey{Name: secretName, Namespace: srcNamespace}, replicate)
+	}
+	return results
+}
+
+// ReferrerKind returns the kind of the referrer that is used to mark replicas of referenced secrets.
+func ReferrerKind(kubeClient client.Client, referrer client.Object) (string, error) {
+	gvk, err := apiutil.GVKForObject(referrer, kubeClient.Scheme())
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(gvk.Kind), nil
+}
+
+func referencedSecret(ctx context.Context, kubeClient client.Client, referrer client.Object, kind string, key client.ObjectKey,
+	replicate func(r *Replicator, ctx context.Context, namespace string) (Result, error)) Result {
+	// only sync secrets that exist in the given namespace
+	secret := &corev1.Secret{}
+	if err := kubeClient.Get(ctx, key, secret); err != nil {
+		return Result{
+			Namespace: referrer.GetNamespace(),
+			Err:       fmt.Errorf("unable to find secret %q: %w", key.Name, err),
+		}
+	}
+
+	// the source has to consent to be replicated into the namespace of the referrer
+	if err := AuthorizePull(ctx, kubeClient, secret, referrer); err != nil {
+		return Result{
+			Namespace: referrer.GetNamespace(),
+			Err:       err,
+		}
+	}
+
+	res, err := replicate(New(kubeClient, secret).ReferencedBy(kind), ctx, referrer.GetNamespace())
+	res.Err = err
+	return res
+}
+
+// Referrers returns the kinds of all referrers of the replica.
+func Referrers(replica client.Object) sets.String {
+	kinds := sets.NewString()
+	for key := range replica.GetAnnotations() {
+		if strings.HasPrefix(key, v1alpha1.SecretReplicationReferencedByAnnotationPrefix) {
+			kinds.Insert(strings.TrimPrefix(key, v1alpha1.SecretReplicationReferencedByAnnotationPrefix))
+		}
+	}
+	return kinds
+}
+
+// ownedBy checks whether the replica may be deleted by replicators of the given referrer kind.
+// Replicas without any referrer have been written by older versions of the controller
+// and are therefore owned by every referrer.
+func ownedBy(replica client.Object, kind string) bool {
+	referrers := Referrers(replica)
+	return referrers.Len() == 0 || referrers.Has(kind)
+}
+
+// referrerAnnotation returns the annotation that marks replicas that are referenced by the given kind.
+func referrerAnnotation(kind string) string {
+	return v1alpha1.SecretReplicationReferencedByAnnotationPrefix + kind
+}
+
+// ReleaseReplica removes the reference of the given kind from the replica.
+// The replica is deleted if it is not referenced by any other kind.
+// Both operations fail with a conflict if the replica has been changed in the meantime
+// so that concurrent references of other kinds are not lost.
+func ReleaseReplica(ctx context.Context, kubeClient client.Client, replica client.Object, kind string) error {
+	if Referrers(replica).Delete(kind).Len() == 0 {
+		resourceVersion := replica.GetResourceVersion()
+		return kubeClient.Delete(ctx, replica, client.Preconditions(metav1.Preconditions{ResourceVersion: &resourceVersion}))
+	}
+
+	patch := client.MergeFromWithOptions(replica.DeepCopyObject().(client.Object), client.MergeFromWithOptimisticLock{})
+	annotations := replica.GetAnnotations()
+	delete(annotations, referrerAnnotation(kind))
+	replica.SetAnnotations(annotations)
+	return kubeClient.Patch(ctx, replica, patch)
+}
+
+// ReferencedSources returns the keys of the source secrets with the given names
+// in the namespace of the from-namespace annotation of the referrer in the format <namespace>/<name>.
+// Returns nil if the referrer does not define a from-namespace annotation.
+func ReferencedSources(referrer client.Object, secretNames []string) []string {
+	srcNamespace, ok := helper.GetAnnotation(referrer, v1alpha1.SecretReplicationFromNamespaceAnnotations)
+	if !ok {
+		return nil
+	}
+	keys := make([]string, len(secretNames))
+	for i, name := range secretNames {
+		keys[i] = types.NamespacedName{Name: name, Namespace: srcNamespace}.String()
+	}
+	return keys
+}
+
+// SourceKeys returns the keys of the sources that are affected by a change of the given secret
+// in the format <namespace>/<name>: the key of the secret itself and the key of its source if it is a replica.
+// It is used to map secrets to their referrers with the SourceSecretIndex.
+func SourceKeys(secret client.Object) []string {
+	keys := sets.NewString(ReplicaOf(secret))
+	if srcKey, ok := SourceOf(secret); ok {
+		keys.Insert(srcKey.String())
+	}
+	return keys.List()
+}
+
+// UnreferencedReplicas returns all secrets in the namespace that are referenced by the given kind
+// but whose source is not in the given set of referenced sources in the format <namespace>/<name>.
+func UnreferencedReplicas(ctx context.Context, kubeClient client.Client, kind, namespace string, referenced sets.String) ([]client.Object, error) {
+	secrets, err := List(ctx, kubeClient, &corev1.Secret{}, client.InNamespace(namespace))
+	if err != nil {
+		return nil, fmt.Errorf("unable to list secrets in namespace %q: %w", namespace, err)
+	}
+	unreferenced := make([]client.Object, 0)
+	for _, secret := range secrets {
+		if !Referrers(secret).Has(kind) {
+			continue
+		}
+		srcKey, ok := SourceOf(secret)
+		if !ok || referenced.Has(srcKey.String()) {
+			continue
+		}
+		unreferenced = append(unreferenced, secret)
+	}
+	return unreferenced, nil
+}
+
+// ReleaseUnreferencedReplicas removes the reference of the given kind from all secrets in the namespace
+// whose source is not in the given set of referenced sources, see UnreferencedReplicas and ReleaseReplica.
+func ReleaseUnreferencedReplicas(ctx context.Context, kubeClient client.Client, kind, namespace string, referenced sets.String) error {
+	replicas, err := UnreferencedReplicas(ctx, kubeClient, kind, namespace, referenced)
+	if err != nil {
+		return err
+	}
+	allErrs := errors.ErrorList{}
+	for _, replica := range replicas {
+		if err := ReleaseReplica(ctx, kubeClient, replica, kind); err != nil && !apierrors.IsNotFound(err) {
+			allErrs = append(allErrs, errors.Error{
+				Src:    replica,
+				Reason: errors.DeleteError,
+				Msg:    fmt.Sprintf("unable to release unreferenced replica of secret %s", replica.GetAnnotations()[v1alpha1.SecretReplicationReplicaOfAnnotation]),
+				Err:    err,
+			})
+		}
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return allErrs
+}
@@ -2,141 +2,443 @@ package replicator
 
 import (
 	"context"
-	"crypto/sha1"
-	"encoding/hex"
-	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
 
-	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
 	"github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+	"github.com/schrodit/secret-replication-controller/pkg/metrics"
 	"k8s.io/apimachinery/pkg/types"
 )
 
+// FieldManager is the name of the field manager that is used to apply replicas.
+// Only fields that are applied by this manager are owned by the controller,
+// so that labels and annotations that are set by other tools on replicas are left untouched.
+// Replicas of other referrers than the replication annotations are applied by their own field manager, see fieldManager.
+const FieldManager = "secret-replication-controller"
+
+// MaxConcurrentReplications is the maximum number of replicas that are written in parallel by ReplicateToAll.
+var MaxConcurrentReplications = 1
+
+// Replicator replicates a source secret or configmap to other namespaces.
+// All replicas that are written by a replicator are marked with the kind of its referrer,
+// replicas are only deleted by the replicator if they are not referenced by other kinds.
 type Replicator struct {
-	client client.Client
-	secret *corev1.Secret
+	client       client.Client
+	src          client.Object
+	referencedBy string
 }
 
-func New(kubeClient client.Client, secret *corev1.Secret) *Replicator {
+// New creates a new replicator for the given source.
+// The source has to be a secret or a configmap.
+// The replicas are referenced by the replication annotations of the source, see AnnotationReferrer.
+func New(kubeClient client.Client, src client.Object) *Replicator {
 	return &Replicator{
-		client: kubeClient,
-		secret: secret,
+		client:       kubeClient,
+		src:          src,
+		referencedBy: AnnotationReferrer,
 	}
 }
 
-// ReplicateTo replicates the secret to the given namespace.
-func (r *Replicator) ReplicateTo(ctx context.Context, namespace string) error {
+// ReferencedBy marks all replicas that are written by the replicator as replicated
+// because of references of resources of the given kind.
+func (r *Replicator) ReferencedBy(kind string) *Replicator {
+	r.referencedBy = kind
+	return r
+}
+
+// ReplicateTo replicates the source to the given namespace.
+func (r *Replicator) ReplicateTo(ctx context.Context, namespace string) (Result, error) {
+	timer := prometheus.NewTimer(metrics.ReplicationDuration.WithLabelValues(KindName(r.src)))
+	defer timer.ObserveDuration()
+
 	log := logr.FromContextOrDiscard(ctx)
 	key := types.NamespacedName{
-		Name:      r.secret.Name,
+		Name:      TargetName(r.src),
 		Namespace: namespace,
 	}
+	kind := KindName(r.src)
 
-	// check if secret is already created
-	repSecret := &corev1.Secret{}
-	if err := r.client.Get(ctx, key, repSecret); err != nil {
-		if !apierrors.IsNotFound(err) {
-			return errors.Error{
-				Src:    r.secret,
-				Reason: errors.InternalError,
-				Msg:    "unable to get secret to create",
+	res, replica, err := r.plan(ctx, key)
+	if err != nil {
+		if res.Action == Skipped {
+			log.V(3).Info("Object in target namespace is not a replica of the source. Skipping...", "target", namespace)
+		}
+		return res, err
+	}
+	switch res.Action {
+	case Unchanged:
+		return res, nil
+	case Created:
+		log.V(3).Info("Replica in target namespace not found. Creating...", "target", namespace)
+		return r.create(ctx, key, res)
+	}
+
+	if typeChanged(r.src, replica) {
+		// the type of a secret is immutable so the replica has to be recreated.
+		log.V(3).Info("Type of the replica differs. Recreating...", "target", namespace)
+		if err := r.client.Delete(ctx, replica); err != nil && !apierrors.IsNotFound(err) {
+			return res, errors.Error{
+				Src:    r.src,
+				Dst:    replica,
+				Reason: errors.DeleteError,
+				Msg:    fmt.Sprintf("unable to delete replicated %s in namespace %s to change its type", kind, key.Namespace),
 				Err:    err,
 			}
 		}
-		log.V(3).Info("Secret in target namespace not found. Creating...", "target", namespace)
-
-		srcHash, err := secretHash(r.secret)
+		res, err = r.create(ctx, key, res)
 		if err != nil {
-			return fmt.Errorf("unable to hash data of source secret: %w", err)
+			return res, err
 		}
+		res.Action = Updated
+		return res, nil
+	}
+	log.V(3).Info("Replica out-of-date. Updating...")
 
-		// secret is not created yet so lets create it
-		repSecret := &corev1.Secret{}
-		repSecret.Name = key.Name
-		repSecret.Namespace = key.Namespace
-		repSecret.Data = r.secret.Data
-		repSecret.Annotations = map[string]string{
-			v1alpha1.SecretReplicationLastObservedHashAnnotation: srcHash,
-			v1alpha1.SecretReplicationReplicaOfAnnotation:        types.NamespacedName{Name: r.secret.Name, Namespace: r.secret.Namespace}.String(),
+	projected, err := project(r.src)
+	if err != nil {
+		return res, err
+	}
+	if err := r.apply(ctx, key, projected, res.Hash); err != nil {
+		return res, errors.Error{
+			Src:    r.src,
+			Dst:    replica,
+			Reason: errors.UpdateError,
+			Msg:    fmt.Sprintf("unable to update replicated %s in namespace %s", kind, key.Namespace),
+			Err:    err,
 		}
+	}
+	if res.Action == Restored {
+		log.V(3).Info("Restored drifted replica", "target", namespace)
+	}
+	return res, nil
+}
 
-		if err := r.client.Create(ctx, repSecret); err != nil {
-			return errors.Error{
-				Src:    r.secret,
-				Reason: errors.CreateError,
-				Msg:    fmt.Sprintf("unable to create replicated secret in namespace %s: %s", key.Namespace, err.Error()),
+// PlanTo returns the result that a replication of the source to the given namespace would have
+// without changing the cluster.
+func (r *Replicator) PlanTo(ctx context.Context, namespace string) (Result, error) {
+	res, _, err := r.plan(ctx, types.NamespacedName{Name: TargetName(r.src), Namespace: namespace})
+	return res, err
+}
+
+// plan determines the action that is needed to bring the replica with the given key up-to-date.
+// The current replica is returned if it exists, it is nil if the replica has to be created.
+// The hash of the result is the hash the replica would be in sync with.
+// Replicas whose type differs from the source are recreated, which is reported as update.
+func (r *Replicator) plan(ctx context.Context, key types.NamespacedName) (Result, client.Object, error) {
+	res := Result{
+		Namespace: key.Namespace,
+	}
+
+	// check if the replica is already created
+	replica, err := NewObject(r.src)
+	if err != nil {
+		return res, nil, err
+	}
+	if err := r.client.Get(ctx, key, replica); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return res, nil, errors.Error{
+				Src:    r.src,
+				Reason: errors.InternalError,
+				Msg:    fmt.Sprintf("unable to get %s to create", KindName(r.src)),
 				Err:    err,
 			}
 		}
-		return nil
+		res.Action = Created
+		return res, nil, nil
 	}
 
-	update, srcHash, err := IsApplicableForUpdate(r.secret, repSecret, false)
+	policy, err := ConflictPolicyFromAnnotations(r.src)
 	if err != nil {
-		return err
+		return res, replica, err
+	}
+	if err := checkConflict(r.src, replica, policy); err != nil {
+		res.Action = Skipped
+		return res, replica, err
+	}
+
+	update, drifted, srcHash, err := needsUpdate(r.src, replica, policy)
+	if err != nil {
+		return res, replica, err
+	}
+	if !update && !Referrers(replica).Has(r.referencedBy) {
+		// the replica is up-to-date but has been written for another referrer so only the reference has to be added.
+		update, srcHash = true, replica.GetAnnotations()[v1alpha1.SecretReplicationLastObservedHashAnnotation]
 	}
 	if !update {
-		return nil
+		res.Action = Unchanged
+		res.Hash = replica.GetAnnotations()[v1alpha1.SecretReplicationLastObservedHashAnnotation]
+		return res, replica, nil
 	}
-	log.V(3).Info("Secret out-of-date. Updating...")
+	res.Action = Updated
+	if drifted && !typeChanged(r.src, replica) {
+		res.Action = Restored
+	}
+	res.Hash = srcHash
+	return res, replica, nil
+}
 
-	repSecret.Data = r.secret.Data
-	metav1.SetMetaDataAnnotation(&repSecret.ObjectMeta, v1alpha1.SecretReplicationLastObservedHashAnnotation, srcHash)
+// create creates a new replica with the given key.
+func (r *Replicator) create(ctx context.Context, key types.NamespacedName, res Result) (Result, error) {
+	kind := KindName(r.src)
+	projected, err := project(r.src)
+	if err != nil {
+		return res, err
+	}
+	srcHash, err := dataHash(projected)
+	if err != nil {
+		return res, fmt.Errorf("unable to hash data of source %s: %w", kind, err)
+	}
 
-	if err := r.client.Update(ctx, repSecret); err != nil {
-		return errors.Error{
-			Src:    r.secret,
-			Dst:    repSecret,
-			Reason: errors.UpdateError,
-			Msg:    fmt.Sprintf("unable to update replicated secret in namespace %s", key.Namespace),
+	if err := r.apply(ctx, key, projected, srcHash); err != nil {
+		return res, errors.Error{
+			Src:    r.src,
+			Reason: errors.CreateError,
+			Msg:    fmt.Sprintf("unable to create replicated %s in namespace %s: %s", kind, key.Namespace, err.Error()),
 			Err:    err,
 		}
 	}
-	return nil
+	res.Action = Created
+	res.Hash = srcHash
+	return res, nil
+}
+
+// apply writes the replica with the given key using server-side apply.
+// The applied object only contains the fields that are managed by the controller:
+// the type, the data and the replicated metadata as well as the replication annotations.
+// Fields that were applied before but are not part of the object anymore are removed by the api server.
+// Data keys that are not part of the source but are owned by other field managers,
+// e.g. of replicas that have been written before server-side apply was used, are removed explicitly.
+func (r *Replicator) apply(ctx context.Context, key types.NamespacedName, projected client.Object, srcHash string) error {
+	replica, err := newApplyObject(r.client, r.src, key)
+	if err != nil {
+		return err
+	}
+	copyType(projected, replica)
+	copyData(projected, replica)
+	r.setMetadata(replica, srcHash)
+	if err := r.client.Patch(ctx, replica, client.Apply, client.FieldOwner(fieldManager(r.referencedBy)), client.ForceOwnership); err != nil {
+		return err
+	}
+
+	applied := replica.DeepCopyObject().(client.Object)
+	if !pruneData(projected, replica) {
+		return nil
+	}
+	return r.client.Patch(ctx, replica, client.MergeFromWithOptions(applied, client.MergeFromWithOptimisticLock{}))
+}
+
+// fieldManager returns the name of the field manager that applies the replicas of the given referrer kind.
+// Every referrer uses its own field manager so that the fields that are only applied by one referrer,
+// like its reference annotation, are not removed by the others.
+func fieldManager(kind string) string {
+	if kind == AnnotationReferrer {
+		return FieldManager
+	}
+	return FieldManager + "-" + kind
+}
+
+// setMetadata sets the replicated labels and annotations of the source
+// as well as the replication annotations on the replica.
+func (r *Replicator) setMetadata(replica client.Object, srcHash string) {
+	applyMetadata(r.src, replica)
+	setAnnotation(replica, v1alpha1.SecretReplicationLastObservedHashAnnotation, srcHash)
+	setAnnotation(replica, v1alpha1.SecretReplicationReplicaOfAnnotation, ReplicaOf(r.src))
+	setAnnotation(replica, referrerAnnotation(r.referencedBy), "true")
+}
+
+// ReplicateToAll replicates the source to all given namespaces.
+// The replicas are written by up to MaxConcurrentReplications workers in parallel.
+// The results are returned in the order of the namespaces, the results of failed replications contain the error.
+func (r *Replicator) ReplicateToAll(ctx context.Context, namespaces []string) []Result {
+	results := make([]Result, len(namespaces))
+	workers := MaxConcurrentReplications
+	if workers > len(namespaces) {
+		workers = len(namespaces)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	indices := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				res, err := r.ReplicateTo(ctx, namespaces[i])
+				res.Err = err
+				results[i] = res
+			}
+		}()
+	}
+	for i := range namespaces {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+	return results
 }
 
 // IsApplicableForUpdate checks whether a resource is applicable for an update.
-// The destination resource is not overwritten if the replicaOf annotation does not matches the source resource.
-// Setting force also updates the destination resource if no replicaOf is set.
+// The destination resource is not overwritten if the replicaOf annotation does not matches the source resource
+// unless the conflict policy allows to take ownership of it.
 // The function returns if the secret is applicated to be updasted, the new src hash and a optional error.
 // The source hash is only returned if the secret should be updated.
-func IsApplicableForUpdate(src, dst *corev1.Secret, force bool) (bool, string, error) {
-	lastObservedHash := dst.Annotations[v1alpha1.SecretReplicationLastObservedHashAnnotation]
+func IsApplicableForUpdate(src, dst client.Object, policy ConflictPolicy) (bool, string, error) {
+	update, _, srcHash, err := needsUpdate(src, dst, policy)
+	return update, srcHash, err
+}
+
+// needsUpdate checks whether the destination has to be updated.
+// In addition to IsApplicableForUpdate it returns whether the data of the destination has drifted,
+// i.e. the data has been changed although the source has not changed since the last sync.
+func needsUpdate(src, dst client.Object, policy ConflictPolicy) (bool, bool, string, error) {
+	lastObservedHash := dst.GetAnnotations()[v1alpha1.SecretReplicationLastObservedHashAnnotation]
 
-	srcHash, err := secretHash(src)
+	projected, err := project(src)
+	if err != nil {
+		return false, false, "", err
+	}
+	srcHash, err := dataHash(projected)
 	if err != nil {
-		return false, "", fmt.Errorf("unable to hash data of source secret: %w", err)
+		return false, false, "", fmt.Errorf("unable to hash data of source %s: %w", KindName(src), err)
+	}
+
+	// do not update if the secret is not controlled by the current secret
+	if !canTakeOwnership(src, dst, policy) {
+		return false, false, "", nil
+	}
+
+	// conflicting objects are always updated to take ownership of them
+	if dst.GetAnnotations()[v1alpha1.SecretReplicationReplicaOfAnnotation] != ReplicaOf(src) {
+		return true, false, srcHash, nil
 	}
 
 	// only update if the observed hash differ
-	if lastObservedHash == srcHash {
-		return false, "", nil
+	if lastObservedHash != srcHash {
+		return true, false, srcHash, nil
 	}
 
-	// do not update if the secret is not controlled by the current secret
-	replicaOf, ok := dst.Annotations[v1alpha1.SecretReplicationReplicaOfAnnotation]
-	if !ok && force {
-		return true, srcHash, nil
+	// the source has not changed so the replica has to be updated if its actual data has been changed.
+	drifted, err := hasDrifted(projected, dst)
+	if err != nil {
+		return false, false, "", err
+	}
+	if drifted {
+		return true, true, srcHash, nil
 	}
+	return false, false, "", nil
+}
 
-	return types.NamespacedName{Name: src.GetName(), Namespace: src.GetNamespace()}.String() == replicaOf, srcHash, nil
+// DeleteReplicasExcept deletes all replicas of the source that are not in one of the given namespaces.
+// Replicas that do not match the current target name of the source are deleted as well.
+// Replicas that are still referenced by other kinds are not deleted, only the reference of the replicator is removed.
+func (r *Replicator) DeleteReplicasExcept(ctx context.Context, namespaces sets.String) error {
+	log := logr.FromContextOrDiscard(ctx)
+	kind := KindName(r.src)
+	replicas, err := r.StaleReplicas(ctx, namespaces)
+	if err != nil {
+		return err
+	}
+
+	allErrs := errors.ErrorList{}
+	for _, replica := range replicas {
+		log.V(3).Info("Replica not needed anymore. Deleting...", "target", replica.GetNamespace())
+		if err := ReleaseReplica(ctx, r.client, replica, r.referencedBy); err != nil && !apierrors.IsNotFound(err) {
+			allErrs = append(allErrs, errors.Error{
+				Src:    r.src,
+				Dst:    replica,
+				Reason: errors.DeleteError,
+				Msg:    fmt.Sprintf("unable to delete replicated %s in namespace %s", kind, replica.GetNamespace()),
+				Err:    err,
+			})
+		}
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return allErrs
 }
 
-// secretHash creates a hash value of the data of the given secret.
-func secretHash(secret *corev1.Secret) (string, error) {
-	// create a hashable representation of the data using json
-	data, err := json.Marshal(secret.Data)
+// StaleReplicas returns all replicas of the source that are not in one of the given namespaces
+// or that do not match the current target name of the source.
+// Only replicas that are referenced by the referrer of the replicator are returned.
+func (r *Replicator) StaleReplicas(ctx context.Context, namespaces sets.String) ([]client.Object, error) {
+	targetName := TargetName(r.src)
+	replicas, err := ListReplicas(ctx, r.client, r.src)
 	if err != nil {
-		return "", err
+		return nil, errors.Error{
+			Src:    r.src,
+			Reason: errors.InternalError,
+			Msg:    fmt.Sprintf("unable to list replicated %ss", KindName(r.src)),
+			Err:    err,
+		}
 	}
 
-	h := sha1.New()
-	_, _ = h.Write(data)
-	return hex.EncodeToString(h.Sum(nil)), nil
+	stale := make([]client.Object, 0)
+	for _, replica := range replicas {
+		if !ownedBy(replica, r.referencedBy) {
+			continue
+		}
+		if namespaces.Has(replica.GetNamespace()) && replica.GetName() == targetName {
+			continue
+		}
+		stale = append(stale, replica)
+	}
+	return stale, nil
+}
+
+// ListReplicas returns all objects that are replicas of the given source.
+func ListReplicas(ctx context.Context, kubeClient client.Client, src client.Object) ([]client.Object, error) {
+	objects, err := List(ctx, kubeClient, src)
+	if err != nil {
+		return nil, err
+	}
+
+	srcKey := ReplicaOf(src)
+	replicas := make([]client.Object, 0)
+	for _, obj := range objects {
+		if obj.GetAnnotations()[v1alpha1.SecretReplicationReplicaOfAnnotation] == srcKey {
+			replicas = append(replicas, obj)
+		}
+	}
+	return replicas, nil
+}
+
+// SourceOf returns the key of the source of the given replica.
+// Returns false if the object is not a replica.
+func SourceOf(replica client.Object) (types.NamespacedName, bool) {
+	replicaOf, ok := replica.GetAnnotations()[v1alpha1.SecretReplicationReplicaOfAnnotation]
+	if !ok {
+		return types.NamespacedName{}, false
+	}
+	parts := strings.SplitN(replicaOf, string(types.Separator), 2)
+	if len(parts) != 2 {
+		return types.NamespacedName{}, false
+	}
+	return types.NamespacedName{Namespace: parts[0], Name: parts[1]}, true
+}
+
+// ReplicaOf returns the value of the replicaOf annotation for replicas of the given source.
+func ReplicaOf(src client.Object) string {
+	return types.NamespacedName{Name: src.GetName(), Namespace: src.GetNamespace()}.String()
+}
+
+// setAnnotation sets the annotation on the given object.
+func setAnnotation(obj client.Object, key, value string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[key] = value
+	obj.SetAnnotations(annotations)
 }
@@ -0,0 +1,114 @@
+package replicator
+
+import (
+	goerrors "errors"
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+)
+
+// Action describes what has been done with a replica.
+type Action string
+
+const (
+	// Created defines that the replica has been created.
+	Created Action = "Created"
+	// Updated defines that the replica has been updated.
+	Updated Action = "Updated"
+	// Unchanged defines that the replica is already up-to-date.
+	Unchanged Action = "Unchanged"
+	// Restored defines that the data of the replica has been changed by someone else and has been restored.
+	Restored Action = "Restored"
+	// Skipped defines that a resource with the name of the replica exists that is not controlled by the source.
+	Skipped Action = "Skipped"
+)
+
+// Result describes the outcome of a replication to a namespace.
+type Result struct {
+	// Cluster is the name of the remote cluster the source has been replicated to.
+	// Empty for replications in the local cluster.
+	Cluster string
+	// Namespace is the namespace the source has been replicated to.
+	Namespace string
+	// Action describes what has been done with the replica.
+	Action Action
+	// Hash is the hash of the source data the replica is in sync with.
+	Hash string
+	// Err is the error that occurred during the replication.
+	Err error
+}
+
+// Changed returns whether the replica has been created or updated.
+func (r Result) Changed() bool {
+	return r.Action == Created || r.Action == Updated || r.Action == Restored
+}
+
+// Message returns a human readable description of the result.
+func (r Result) Message() string {
+	if r.Action == Restored {
+		if len(r.Cluster) != 0 {
+			return fmt.Sprintf("Corrected drift of replica in namespace %s of cluster %s", r.Namespace, r.Cluster)
+		}
+		return fmt.Sprintf("Corrected drift of replica in namespace %s", r.Namespace)
+	}
+	if len(r.Cluster) != 0 {
+		return fmt.Sprintf("%s replica in namespace %s of cluster %s", r.Action, r.Namespace, r.Cluster)
+	}
+	return fmt.Sprintf("%s replica in namespace %s", r.Action, r.Namespace)
+}
+
+// TargetStatuses computes the status of all replication targets from the results of a replication of the given source.
+// The last sync time of the previous status of a target is kept if its replica has not been changed.
+func TargetStatuses(src client.Object, previous []v1alpha1.ReplicationTargetStatus, results []Result) []v1alpha1.ReplicationTargetStatus {
+	type target struct{ cluster, namespace string }
+	previousByTarget := make(map[target]v1alpha1.ReplicationTargetStatus, len(previous))
+	for _, status := range previous {
+		previousByTarget[target{status.Cluster, status.Namespace}] = status
+	}
+
+	// only use a precision of seconds as the time is serialized as RFC3339 anyway.
+	now := metav1.Now().Rfc3339Copy()
+	statuses := make([]v1alpha1.ReplicationTargetStatus, 0, len(results))
+	for _, res := range results {
+		prev, hasPrevious := previousByTarget[target{res.Cluster, res.Namespace}]
+		status := v1alpha1.ReplicationTargetStatus{
+			Cluster:        res.Cluster,
+			Namespace:      res.Namespace,
+			LastSyncedHash: prev.LastSyncedHash,
+			LastSyncTime:   prev.LastSyncTime,
+		}
+
+		switch {
+		case res.Action == Skipped:
+			// conflicts are returned as skipped results with the conflict as error
+			status.Reason = string(Skipped)
+			status.Message = fmt.Sprintf("a %s that is not a replica of the source already exists", KindName(src))
+		case res.Err != nil:
+			status.Reason = string(errors.InternalError)
+			var intErr errors.Error
+			if goerrors.As(res.Err, &intErr) {
+				status.Reason = string(intErr.Reason)
+			}
+			status.Message = res.Err.Error()
+		case res.Action == Unchanged && hasPrevious && prev.LastSyncedHash == res.Hash && prev.LastSyncTime != nil:
+			// nothing has changed so the previous sync time is still valid
+		default:
+			status.LastSyncedHash = res.Hash
+			status.LastSyncTime = &now
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Cluster != statuses[j].Cluster {
+			return statuses[i].Cluster < statuses[j].Cluster
+		}
+		return statuses[i].Namespace < statuses[j].Namespace
+	})
+	return statuses
+}
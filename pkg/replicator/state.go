@@ -0,0 +1,61 @@
+package replicator
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+)
+
+// ReplicaState describes whether a replica matches the current data of its source.
+type ReplicaState string
+
+const (
+	// InSync defines that the replica contains the current data of its source.
+	InSync ReplicaState = "in-sync"
+	// Stale defines that the source has changed since the replica has been synced the last time.
+	Stale ReplicaState = "stale"
+	// Drifted defines that the source has not changed but the data of the replica has been changed by someone else.
+	Drifted ReplicaState = "drifted"
+)
+
+// StateOf compares the replica with the current data of the source.
+// In addition the hash of the projected source data is returned.
+func StateOf(src, replica client.Object) (ReplicaState, string, error) {
+	projected, err := project(src)
+	if err != nil {
+		return "", "", err
+	}
+	srcHash, err := dataHash(projected)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to hash data of source %s: %w", KindName(src), err)
+	}
+	if replica.GetAnnotations()[v1alpha1.SecretReplicationLastObservedHashAnnotation] != srcHash {
+		return Stale, srcHash, nil
+	}
+
+	drifted, err := hasDrifted(projected, replica)
+	if err != nil {
+		return "", "", err
+	}
+	if drifted {
+		return Drifted, srcHash, nil
+	}
+	return InSync, srcHash, nil
+}
+
+// hasDrifted checks whether the data of the replica differs from the projected source data.
+// Keys that are not part of the source are considered as drift as well,
+// so that keys that have been removed from the source, e.g. revoked credentials, do not remain on replicas.
+func hasDrifted(projected, replica client.Object) (bool, error) {
+	expected, err := contentHash(projected)
+	if err != nil {
+		return false, fmt.Errorf("unable to hash data of source %s: %w", KindName(projected), err)
+	}
+	actual, err := contentHash(replica)
+	if err != nil {
+		return false, fmt.Errorf("unable to hash data of replica %s: %w", KindName(replica), err)
+	}
+	return expected != actual, nil
+}
@@ -0,0 +1,220 @@
+package replicator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1/helper"
+	"github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+)
+
+// Targets describes the namespaces a source should be replicated to.
+type Targets struct {
+	// All defines that the source should be replicated to all namespaces.
+	All bool
+	// Namespaces defines names and patterns of namespaces the source should be replicated to.
+	Namespaces *helper.NamespaceMatcher
+	// Selector defines a label selector for namespaces the source should be replicated to.
+	Selector labels.Selector
+	// Exclude defines names and patterns of namespaces the source should never be replicated to.
+	Exclude *helper.NamespaceMatcher
+}
+
+// TargetsFromAnnotations parses the replication targets from the annotations of the given object.
+// Returns false if the object does not define any targets.
+func TargetsFromAnnotations(obj client.Object) (*Targets, bool, error) {
+	namespacesVal, hasNamespacesAnn := helper.GetAnnotation(obj, v1alpha1.SecretReplicationNamespacesAnnotations)
+	selectorVal, hasNamespaceSelectorAnn := helper.GetAnnotation(obj, v1alpha1.SecretReplicationNamespaceSelectorAnnotations)
+	_, hasAllNamespacesAnn := helper.GetAnnotation(obj, v1alpha1.SecretReplicationAllNamespacesAnnotations)
+	if !hasNamespacesAnn && !hasNamespaceSelectorAnn && !hasAllNamespacesAnn {
+		return nil, false, nil
+	}
+
+	targets := &Targets{
+		All: hasAllNamespacesAnn,
+	}
+	if hasNamespacesAnn {
+		matcher, err := helper.ParseNamespaceList(namespacesVal)
+		if err != nil {
+			return nil, true, errors.Error{
+				Src:    obj,
+				Reason: errors.InvalidNamespace,
+				Msg:    "unable to parse namespaces",
+				Err:    err,
+			}
+		}
+		targets.Namespaces = matcher
+	}
+	if hasNamespaceSelectorAnn {
+		selector, err := labels.Parse(selectorVal)
+		if err != nil {
+			return nil, true, errors.Error{
+				Src:    obj,
+				Reason: errors.InvalidNamespaceSelector,
+				Msg:    fmt.Sprintf("unable to parse namespace selector %q", selectorVal),
+				Err:    err,
+			}
+		}
+		targets.Selector = selector
+	}
+	if excludeVal, ok := helper.GetAnnotation(obj, v1alpha1.SecretReplicationExcludeNamespacesAnnotations); ok {
+		exclude, err := helper.ParseNamespaceList(excludeVal)
+		if err != nil {
+			return nil, true, errors.Error{
+				Src:    obj,
+				Reason: errors.InvalidNamespace,
+				Msg:    "unable to parse excluded namespaces",
+				Err:    err,
+			}
+		}
+		targets.Exclude = exclude
+	}
+	return targets, true, nil
+}
+
+// TargetsFromPolicy returns the replication targets of the given replication policy.
+func TargetsFromPolicy(policy *v1alpha1.ReplicationPolicy) (*Targets, error) {
+	targets := &Targets{
+		All: policy.Spec.AllNamespaces,
+	}
+	if len(policy.Spec.Namespaces) != 0 {
+		matcher, err := helper.ParseNamespaceList(strings.Join(policy.Spec.Namespaces, ","))
+		if err != nil {
+			return nil, errors.Error{
+				Src:    policy,
+				Reason: errors.InvalidNamespace,
+				Msg:    "unable to parse namespaces",
+				Err:    err,
+			}
+		}
+		targets.Namespaces = matcher
+	}
+	if policy.Spec.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(policy.Spec.NamespaceSelector)
+		if err != nil {
+			return nil, errors.Error{
+				Src:    policy,
+				Reason: errors.InvalidNamespaceSelector,
+				Msg:    "unable to parse namespace selector",
+				Err:    err,
+			}
+		}
+		targets.Selector = selector
+	}
+	if len(policy.Spec.ExcludeNamespaces) != 0 {
+		exclude, err := helper.ParseNamespaceList(strings.Join(policy.Spec.ExcludeNamespaces, ","))
+		if err != nil {
+			return nil, errors.Error{
+				Src:    policy,
+				Reason: errors.InvalidNamespace,
+				Msg:    "unable to parse excluded namespaces",
+				Err:    err,
+			}
+		}
+		targets.Exclude = exclude
+	}
+	return targets, nil
+}
+
+// Matches checks whether the given namespace is a target.
+func (t *Targets) Matches(namespace client.Object) bool {
+	if t.Exclude != nil && t.Exclude.Matches(namespace.GetName()) {
+		return false
+	}
+	if t.All {
+		return true
+	}
+	if t.Namespaces != nil && t.Namespaces.Matches(namespace.GetName()) {
+		return true
+	}
+	if t.Selector != nil && t.Selector.Matches(labels.Set(namespace.GetLabels())) {
+		return true
+	}
+	return false
+}
+
+// Resolve returns the names of all namespaces that are targeted.
+// Explicitly named namespaces have to exist, namespaces that are marked for deletion are ignored otherwise.
+// The given source is only used for error reporting.
+func (t *Targets) Resolve(ctx context.Context, kubeClient client.Client, src client.Object) (sets.String, error) {
+	namespaces := sets.NewString()
+
+	if t.Namespaces != nil && !t.All {
+		// lets validate here if the namespace exists
+		for _, nsName := range t.Namespaces.Names() {
+			ns := &corev1.Namespace{}
+			if err := kubeClient.Get(ctx, types.NamespacedName{Name: nsName}, ns); err != nil {
+				return nil, errors.Error{
+					Src:    src,
+					Reason: errors.InvalidNamespace,
+					Err:    err,
+				}
+			}
+			if !ns.DeletionTimestamp.IsZero() {
+				return nil, errors.Error{
+					Src:    src,
+					Reason: errors.InvalidNamespace,
+					Msg:    fmt.Sprintf("namespace %s is marked for deletion", nsName),
+				}
+			}
+			namespaces.Insert(nsName)
+		}
+	}
+
+	if t.Selector != nil && !t.All {
+		nsList := &corev1.NamespaceList{}
+		if err := kubeClient.List(ctx, nsList, client.MatchingLabelsSelector{Selector: t.Selector}); err != nil {
+			return nil, errors.Error{
+				Src:    src,
+				Reason: errors.InvalidNamespace,
+				Msg:    fmt.Sprintf("unable to list namespaces matching %q", t.Selector.String()),
+				Err:    err,
+			}
+		}
+		for _, ns := range nsList.Items {
+			// do not replicate into namespaces that are already marked for deletion
+			if ns.DeletionTimestamp.IsZero() {
+				namespaces.Insert(ns.Name)
+			}
+		}
+	}
+
+	if t.All || (t.Namespaces != nil && t.Namespaces.HasPatterns()) {
+		nsList := &corev1.NamespaceList{}
+		if err := kubeClient.List(ctx, nsList); err != nil {
+			return nil, errors.Error{
+				Src:    src,
+				Reason: errors.InvalidNamespace,
+				Msg:    "unable to list all namespaces",
+				Err:    err,
+			}
+		}
+		for _, ns := range nsList.Items {
+			if !ns.DeletionTimestamp.IsZero() {
+				continue
+			}
+			if t.All || t.Namespaces.Matches(ns.Name) {
+				namespaces.Insert(ns.Name)
+			}
+		}
+	}
+
+	if t.Exclude != nil {
+		for _, namespace := range namespaces.List() {
+			if t.Exclude.Matches(namespace) {
+				namespaces.Delete(namespace)
+			}
+		}
+	}
+
+	return namespaces, nil
+}
@@ -0,0 +1,63 @@
+package replicator
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1/helper"
+	"github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+)
+
+// ValidateAnnotations parses all replication annotations of the given object without accessing the cluster.
+// All invalid annotations are returned as list of errors so that they can be reported at once.
+func ValidateAnnotations(obj client.Object) error {
+	allErrs := errors.ErrorList{}
+	if _, _, err := TargetsFromAnnotations(obj); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	_, hasAllNamespacesAnn := helper.GetAnnotation(obj, v1alpha1.SecretReplicationAllNamespacesAnnotations)
+	_, hasNamespacesAnn := helper.GetAnnotation(obj, v1alpha1.SecretReplicationNamespacesAnnotations)
+	if hasAllNamespacesAnn && hasNamespacesAnn {
+		allErrs = append(allErrs, errors.Error{
+			Src:    obj,
+			Reason: errors.InvalidNamespace,
+			Msg:    "replication to all namespaces cannot be combined with a list of namespaces",
+		})
+	}
+	if _, err := projectionFromAnnotations(obj); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if _, err := ConflictPolicyFromAnnotations(obj); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if _, _, err := PullSource(obj); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if val, ok := helper.GetAnnotation(obj, v1alpha1.SecretReplicationAllowedNamespacesAnnotations); ok {
+		if _, err := helper.ParseNamespaceList(val); err != nil {
+			allErrs = append(allErrs, errors.Error{
+				Src:    obj,
+				Reason: errors.InvalidNamespace,
+				Msg:    "unable to parse allowed namespaces",
+				Err:    err,
+			})
+		}
+	}
+	if val, ok := helper.GetAnnotation(obj, v1alpha1.SecretReplicationAllowedNamespaceSelectorAnnotations); ok {
+		if _, err := labels.Parse(val); err != nil {
+			allErrs = append(allErrs, errors.Error{
+				Src:    obj,
+				Reason: errors.InvalidNamespaceSelector,
+				Msg:    fmt.Sprintf("unable to parse allowed namespace selector %q", val),
+				Err:    err,
+			})
+		}
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return allErrs
+}
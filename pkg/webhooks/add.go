@@ -0,0 +1,62 @@
+package webhooks
+
+import (
+	"github.com/go-logr/logr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+)
+
+const (
+	// ValidateSecretPath is the path of the webhook that validates the replication annotations of secrets.
+	ValidateSecretPath = "/validate-secret"
+	// ValidateIngressPath is the path of the webhook that validates the replication annotations of ingresses.
+	ValidateIngressPath = "/validate-ingress"
+	// InjectReplicasPath is the path of the webhook that ensures that replicas exist
+	// when secrets or service accounts that depend on them are created.
+	InjectReplicasPath = "/inject-replicas"
+)
+
+// AddToMgr registers the admission webhooks at the webhook server of the given manager.
+func AddToMgr(log logr.Logger, mgr ctrl.Manager) error {
+	decoder, err := admission.NewDecoder(mgr.GetScheme())
+	if err != nil {
+		return err
+	}
+	server := mgr.GetWebhookServer()
+	server.Register(ValidateSecretPath, &webhook.Admission{
+		Handler: NewSecretValidator(log.WithName("secret"), mgr.GetClient(), decoder),
+	})
+	server.Register(ValidateIngressPath, &webhook.Admission{
+		Handler: NewIngressValidator(log.WithName("ingress"), mgr.GetClient(), decoder),
+	})
+	server.Register(InjectReplicasPath, &webhook.Admission{
+		Handler: NewReplicaInjector(log.WithName("injector"), mgr.GetClient(), decoder),
+	})
+	return nil
+}
+
+// deniedMessage returns the message of a denied admission request for the given possibly aggregated error.
+func deniedMessage(err error) string {
+	if errs, ok := err.(errors.ErrorList); ok {
+		return errs.AggregateError().Error()
+	}
+	return err.Error()
+}
+
+// annotationsChanged checks whether one of the replication annotations differs between the old and the new object.
+func annotationsChanged(oldAnnotations, newAnnotations map[string]string) bool {
+	for _, set := range v1alpha1.UserFacingAnnotationSets() {
+		for _, key := range set.List() {
+			oldVal, oldOk := oldAnnotations[key]
+			newVal, newOk := newAnnotations[key]
+			if oldOk != newOk || oldVal != newVal {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1/helper"
+	"github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+	ingressctrl "github.com/schrodit/secret-replication-controller/pkg/controllers/ingress"
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+)
+
+// IngressValidator rejects ingresses that reference tls secrets of a namespace that does not allow their namespace.
+type IngressValidator struct {
+	log     logr.Logger
+	client  ctrlclient.Client
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = &IngressValidator{}
+
+// NewIngressValidator creates a new validating webhook handler for ingresses.
+func NewIngressValidator(log logr.Logger, client ctrlclient.Client, decoder *admission.Decoder) *IngressValidator {
+	return &IngressValidator{
+		log:     log,
+		client:  client,
+		decoder: decoder,
+	}
+}
+
+// Handle validates the ingress of the admission request.
+func (v *IngressValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	ingress, err := v.newIngress(req)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := v.decoder.Decode(req, ingress); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if len(ingress.GetNamespace()) == 0 {
+		ingress.SetNamespace(req.Namespace)
+	}
+
+	if req.Operation == admissionv1.Update {
+		oldIngress, _ := v.newIngress(req)
+		if err := v.decoder.DecodeRaw(req.OldObject, oldIngress); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if !annotationsChanged(oldIngress.GetAnnotations(), ingress.GetAnnotations()) &&
+			!secretsChanged(oldIngress, ingress) {
+			return admission.Allowed("")
+		}
+	}
+
+	srcNamespace, ok := helper.GetAnnotation(ingress, v1alpha1.SecretReplicationFromNamespaceAnnotations)
+	if !ok {
+		return admission.Allowed("")
+	}
+	if len(strings.TrimSpace(srcNamespace)) == 0 {
+		return admission.Denied("the namespace of the tls secrets must not be empty")
+	}
+
+	allErrs := errors.ErrorList{}
+	for _, secretName := range ingressctrl.SecretsFromIngress(ingress) {
+		if err := v.authorizePull(ctx, ingress, ctrlclient.ObjectKey{Name: secretName, Namespace: srcNamespace}); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+	if len(allErrs) != 0 {
+		v.log.V(3).Info("denied ingress", "name", ingress.GetName(), "namespace", ingress.GetNamespace())
+		return admission.Denied(deniedMessage(allErrs))
+	}
+	return admission.Allowed("")
+}
+
+// newIngress returns an empty ingress of the api version of the admission request.
+func (v *IngressValidator) newIngress(req admission.Request) (ctrlclient.Object, error) {
+	switch req.Kind.Version {
+	case networkingv1.SchemeGroupVersion.Version:
+		return &networkingv1.Ingress{}, nil
+	case networkingv1beta1.SchemeGroupVersion.Version:
+		return &networkingv1beta1.Ingress{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported ingress api version %q", req.Kind.Version)
+	}
+}
+
+// authorizePull checks whether the referenced tls secret allows the namespace of the ingress.
+// Secrets that do not exist yet are not checked as they are reported by the controller.
+func (v *IngressValidator) authorizePull(ctx context.Context, ingress ctrlclient.Object, key ctrlclient.ObjectKey) error {
+	src := &corev1.Secret{}
+	if err := v.client.Get(ctx, key, src); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("unable to get secret %s: %w", key.String(), err)
+	}
+	return replicator.AuthorizePull(ctx, v.client, src, ingress)
+}
+
+// secretsChanged checks whether the tls secrets of the old and the new ingress differ.
+func secretsChanged(oldIngress, newIngress ctrlclient.Object) bool {
+	return strings.Join(ingressctrl.SecretsFromIngress(oldIngress), ",") != strings.Join(ingressctrl.SecretsFromIngress(newIngress), ",")
+}
@@ -0,0 +1,127 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1/helper"
+	"github.com/schrodit/secret-replication-controller/pkg/controllers/errors"
+	serviceaccountctrl "github.com/schrodit/secret-replication-controller/pkg/controllers/serviceaccount"
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+)
+
+// ReplicaInjector ensures that replicated secrets exist when objects that depend on them are created,
+// so that workloads do not have to wait for the controller.
+// Secrets that pull a source are filled with the data of the source
+// and the image pull secrets of service accounts are replicated before the service account is created.
+// The injector never denies requests, failed injections are retried by the controllers.
+type ReplicaInjector struct {
+	log     logr.Logger
+	client  ctrlclient.Client
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = &ReplicaInjector{}
+
+// NewReplicaInjector creates a new mutating webhook handler for secrets and service accounts.
+func NewReplicaInjector(log logr.Logger, client ctrlclient.Client, decoder *admission.Decoder) *ReplicaInjector {
+	return &ReplicaInjector{
+		log:     log,
+		client:  client,
+		decoder: decoder,
+	}
+}
+
+// Handle injects the replicas that are needed by the object of the admission request.
+func (i *ReplicaInjector) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create {
+		return admission.Allowed("")
+	}
+	switch req.Kind.Kind {
+	case "Secret":
+		return i.injectSecret(ctx, req)
+	case "ServiceAccount":
+		return i.ensureImagePullSecrets(ctx, req)
+	default:
+		return admission.Allowed("")
+	}
+}
+
+// injectSecret fills a secret that pulls a source with the data of the source.
+func (i *ReplicaInjector) injectSecret(ctx context.Context, req admission.Request) admission.Response {
+	secret := &corev1.Secret{}
+	if err := i.decoder.Decode(req, secret); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if len(secret.Namespace) == 0 {
+		secret.Namespace = req.Namespace
+	}
+	srcKey, ok, err := replicator.PullSource(secret)
+	if err != nil || !ok {
+		return admission.Allowed("")
+	}
+
+	src := &corev1.Secret{}
+	if err := i.client.Get(ctx, srcKey, src); err != nil {
+		i.log.V(3).Info("unable to get source of pulling secret", "source", srcKey.String(), "error", err.Error())
+		return admission.Allowed("")
+	}
+	if err := replicator.AuthorizePull(ctx, i.client, src, secret); err != nil {
+		i.log.V(3).Info("source does not allow to be pulled", "source", srcKey.String(), "error", err.Error())
+		return admission.Allowed("")
+	}
+	if err := replicator.New(i.client, src).Inject(secret); err != nil {
+		i.log.V(3).Info("unable to inject data of source", "source", srcKey.String(), "error", err.Error())
+		return admission.Allowed("")
+	}
+
+	raw, err := json.Marshal(secret)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, raw)
+}
+
+// ensureImagePullSecrets replicates the image pull secrets of a service account into its namespace.
+// The replication is skipped for dry run requests as it has side effects.
+func (i *ReplicaInjector) ensureImagePullSecrets(ctx context.Context, req admission.Request) admission.Response {
+	if req.DryRun != nil && *req.DryRun {
+		return admission.Allowed("")
+	}
+	sa := &corev1.ServiceAccount{}
+	if err := i.decoder.Decode(req, sa); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if len(sa.Namespace) == 0 {
+		sa.Namespace = req.Namespace
+	}
+	srcNamespace, ok := helper.GetAnnotation(sa, v1alpha1.SecretReplicationFromNamespaceAnnotations)
+	if !ok {
+		return admission.Allowed("")
+	}
+	usedSecrets := serviceaccountctrl.ImagePullSecrets(sa)
+	if len(usedSecrets) == 0 {
+		return admission.Allowed("")
+	}
+
+	allErrs := errors.ErrorList{}
+	for _, res := range replicator.ReplicateReferencedSecrets(ctx, i.client, sa, srcNamespace, usedSecrets) {
+		if res.Err != nil {
+			allErrs = append(allErrs, res.Err)
+		}
+	}
+	if len(allErrs) != 0 {
+		i.log.V(3).Info(fmt.Sprintf("unable to replicate image pull secrets of service account %s/%s", sa.Namespace, sa.Name),
+			"error", deniedMessage(allErrs))
+	}
+	return admission.Allowed("")
+}
@@ -0,0 +1,98 @@
+package webhooks_test
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/webhooks"
+)
+
+var _ = Describe("replica injector", func() {
+
+	var (
+		injector *webhooks.ReplicaInjector
+		src      *corev1.Secret
+		ns       *corev1.Namespace
+	)
+
+	BeforeEach(func() {
+		ctx := context.Background()
+		decoder, err := admission.NewDecoder(scheme.Scheme)
+		Expect(err).ToNot(HaveOccurred())
+		injector = webhooks.NewReplicaInjector(logr.Discard(), client, decoder)
+
+		ns = &corev1.Namespace{}
+		ns.GenerateName = "e2e-"
+		Expect(client.Create(ctx, ns)).To(Succeed())
+
+		src = &corev1.Secret{}
+		src.GenerateName = "e2e-"
+		src.Namespace = "default"
+		src.Annotations = map[string]string{
+			v1alpha1.SecretReplicationAllowedNamespacesAnnotation: ns.Name,
+		}
+		src.Data = map[string][]byte{
+			"key": []byte("value"),
+		}
+		Expect(client.Create(ctx, src)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		ctx := context.Background()
+		Expect(client.Delete(ctx, src)).To(Succeed())
+		Expect(client.Delete(ctx, ns)).To(Succeed())
+	})
+
+	It("should fill a pulling secret with the data of its source", func() {
+		secret := &corev1.Secret{}
+		secret.Name = "test"
+		secret.Namespace = ns.Name
+		secret.Annotations = map[string]string{
+			v1alpha1.SecretReplicationReplicateFromAnnotation: src.Namespace + "/" + src.Name,
+		}
+
+		res := injector.Handle(context.Background(), admissionRequest(admissionv1.Create, secret, nil))
+		Expect(res.Allowed).To(BeTrue())
+		Expect(res.Patches).ToNot(BeEmpty())
+	})
+
+	It("should not fill a pulling secret if the source does not allow its namespace", func() {
+		secret := &corev1.Secret{}
+		secret.Name = "test"
+		secret.Namespace = "default"
+		secret.Annotations = map[string]string{
+			v1alpha1.SecretReplicationReplicateFromAnnotation: src.Namespace + "/" + src.Name,
+		}
+
+		res := injector.Handle(context.Background(), admissionRequest(admissionv1.Create, secret, nil))
+		Expect(res.Allowed).To(BeTrue())
+		Expect(res.Patches).To(BeEmpty())
+	})
+
+	It("should replicate the image pull secrets of a service account before it is created", func() {
+		ctx := context.Background()
+		sa := &corev1.ServiceAccount{}
+		sa.Name = "test"
+		sa.Namespace = ns.Name
+		sa.Annotations = map[string]string{
+			v1alpha1.SecretReplicationFromNamespaceAnnotation: src.Namespace,
+		}
+		sa.ImagePullSecrets = []corev1.LocalObjectReference{{Name: src.Name}}
+
+		res := injector.Handle(ctx, admissionRequest(admissionv1.Create, sa, nil))
+		Expect(res.Allowed).To(BeTrue())
+
+		replica := &corev1.Secret{}
+		Expect(client.Get(ctx, types.NamespacedName{Name: src.Name, Namespace: ns.Name}, replica)).To(Succeed())
+		Expect(replica.Data).To(Equal(src.Data))
+	})
+})
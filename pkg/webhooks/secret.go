@@ -0,0 +1,85 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/schrodit/secret-replication-controller/pkg/replicator"
+)
+
+// SecretValidator rejects secrets with invalid replication annotations
+// and secrets that pull sources that do not allow their namespace.
+type SecretValidator struct {
+	log     logr.Logger
+	client  ctrlclient.Client
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = &SecretValidator{}
+
+// NewSecretValidator creates a new validating webhook handler for secrets.
+func NewSecretValidator(log logr.Logger, client ctrlclient.Client, decoder *admission.Decoder) *SecretValidator {
+	return &SecretValidator{
+		log:     log,
+		client:  client,
+		decoder: decoder,
+	}
+}
+
+// Handle validates the secret of the admission request.
+func (v *SecretValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	secret := &corev1.Secret{}
+	if err := v.decoder.Decode(req, secret); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if len(secret.Namespace) == 0 {
+		secret.Namespace = req.Namespace
+	}
+
+	if req.Operation == admissionv1.Update {
+		oldSecret := &corev1.Secret{}
+		if err := v.decoder.DecodeRaw(req.OldObject, oldSecret); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		// the controller itself has to be able to update secrets with invalid annotations,
+		// e.g. to remove its finalizer, so only changed annotations are validated.
+		if !annotationsChanged(oldSecret.Annotations, secret.Annotations) {
+			return admission.Allowed("")
+		}
+	}
+
+	if err := replicator.ValidateAnnotations(secret); err != nil {
+		v.log.V(3).Info("denied secret with invalid annotations", "name", secret.Name, "namespace", secret.Namespace)
+		return admission.Denied(deniedMessage(err))
+	}
+	if err := v.authorizePull(ctx, secret); err != nil {
+		v.log.V(3).Info("denied pulling secret", "name", secret.Name, "namespace", secret.Namespace)
+		return admission.Denied(deniedMessage(err))
+	}
+	return admission.Allowed("")
+}
+
+// authorizePull checks whether the source of a pulling secret allows the namespace of the secret.
+// Sources that do not exist yet are not checked as they are reported by the controller.
+func (v *SecretValidator) authorizePull(ctx context.Context, secret *corev1.Secret) error {
+	srcKey, ok, err := replicator.PullSource(secret)
+	if err != nil || !ok {
+		return err
+	}
+	src := &corev1.Secret{}
+	if err := v.client.Get(ctx, srcKey, src); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("unable to get source %s: %w", srcKey.String(), err)
+	}
+	return replicator.AuthorizePull(ctx, v.client, src, secret)
+}
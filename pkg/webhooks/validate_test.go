@@ -0,0 +1,206 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/schrodit/secret-replication-controller/pkg/apis/core/v1alpha1"
+	"github.com/schrodit/secret-replication-controller/pkg/webhooks"
+)
+
+var _ = Describe("validating webhooks", func() {
+
+	var (
+		decoder    *admission.Decoder
+		namespaces []string
+	)
+
+	BeforeEach(func() {
+		var err error
+		decoder, err = admission.NewDecoder(scheme.Scheme)
+		Expect(err).ToNot(HaveOccurred())
+		namespaces = make([]string, 0)
+	})
+
+	AfterEach(func() {
+		ctx := context.Background()
+		for _, ns := range namespaces {
+			namespace := &corev1.Namespace{}
+			namespace.Name = ns
+			Expect(client.Delete(ctx, namespace)).To(Succeed())
+		}
+	})
+
+	createNamespace := func(ctx context.Context) *corev1.Namespace {
+		ns := &corev1.Namespace{}
+		ns.GenerateName = "e2e-"
+		Expect(client.Create(ctx, ns)).To(Succeed())
+		namespaces = append(namespaces, ns.Name)
+		return ns
+	}
+
+	Context("secrets", func() {
+		It("should allow secrets with valid annotations", func() {
+			secret := &corev1.Secret{}
+			secret.Name = "test"
+			secret.Namespace = "default"
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationNamespacesAnnotation:        "a, b, e2e-*",
+				v1alpha1.SecretReplicationNamespaceSelectorAnnotation: "env=prod",
+				v1alpha1.SecretReplicationKeyMappingAnnotation:        "a=b",
+			}
+			validator := webhooks.NewSecretValidator(logr.Discard(), client, decoder)
+			res := validator.Handle(context.Background(), admissionRequest(admissionv1.Create, secret, nil))
+			Expect(res.Allowed).To(BeTrue())
+		})
+
+		It("should deny secrets with malformed annotations", func() {
+			secret := &corev1.Secret{}
+			secret.Name = "test"
+			secret.Namespace = "default"
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationNamespacesAnnotation:        "/[/",
+				v1alpha1.SecretReplicationNamespaceSelectorAnnotation: "env in (",
+				v1alpha1.SecretReplicationKeyMappingAnnotation:        "a",
+			}
+			validator := webhooks.NewSecretValidator(logr.Discard(), client, decoder)
+			res := validator.Handle(context.Background(), admissionRequest(admissionv1.Create, secret, nil))
+			Expect(res.Allowed).To(BeFalse())
+			Expect(res.Result.Message).To(ContainSubstring("unable to parse namespaces"))
+			Expect(res.Result.Message).To(ContainSubstring("unable to parse namespace selector"))
+			Expect(res.Result.Message).To(ContainSubstring("invalid key mapping"))
+		})
+
+		It("should deny secrets that combine all namespaces with a list of namespaces", func() {
+			secret := &corev1.Secret{}
+			secret.Name = "test"
+			secret.Namespace = "default"
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationAllNamespacesAnnotation: "true",
+				v1alpha1.SecretReplicationNamespacesAnnotation:    "a",
+			}
+			validator := webhooks.NewSecretValidator(logr.Discard(), client, decoder)
+			res := validator.Handle(context.Background(), admissionRequest(admissionv1.Create, secret, nil))
+			Expect(res.Allowed).To(BeFalse())
+		})
+
+		It("should allow updates that do not change the replication annotations", func() {
+			secret := &corev1.Secret{}
+			secret.Name = "test"
+			secret.Namespace = "default"
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationKeyMappingAnnotation: "a",
+			}
+			oldSecret := secret.DeepCopy()
+			secret.Finalizers = []string{v1alpha1.SecretReplicationFinalizer}
+			validator := webhooks.NewSecretValidator(logr.Discard(), client, decoder)
+			res := validator.Handle(context.Background(), admissionRequest(admissionv1.Update, secret, oldSecret))
+			Expect(res.Allowed).To(BeTrue())
+		})
+
+		It("should deny secrets that pull a source that does not allow their namespace", func() {
+			ctx := context.Background()
+			ns := createNamespace(ctx)
+
+			src := &corev1.Secret{}
+			src.GenerateName = "e2e-"
+			src.Namespace = "default"
+			src.Annotations = map[string]string{
+				v1alpha1.SecretReplicationAllowedNamespacesAnnotation: "other",
+			}
+			Expect(client.Create(ctx, src)).To(Succeed())
+			defer func() {
+				Expect(client.Delete(ctx, src)).To(Succeed())
+			}()
+
+			secret := &corev1.Secret{}
+			secret.Name = "test"
+			secret.Namespace = ns.Name
+			secret.Annotations = map[string]string{
+				v1alpha1.SecretReplicationReplicateFromAnnotation: src.Namespace + "/" + src.Name,
+			}
+			validator := webhooks.NewSecretValidator(logr.Discard(), client, decoder)
+			res := validator.Handle(ctx, admissionRequest(admissionv1.Create, secret, nil))
+			Expect(res.Allowed).To(BeFalse())
+
+			src.Annotations[v1alpha1.SecretReplicationAllowedNamespacesAnnotation] = ns.Name
+			Expect(client.Update(ctx, src)).To(Succeed())
+			res = validator.Handle(ctx, admissionRequest(admissionv1.Create, secret, nil))
+			Expect(res.Allowed).To(BeTrue())
+		})
+	})
+
+	Context("ingresses", func() {
+		It("should deny ingresses that reference tls secrets that do not allow their namespace", func() {
+			ctx := context.Background()
+			ns := createNamespace(ctx)
+
+			src := &corev1.Secret{}
+			src.GenerateName = "e2e-"
+			src.Namespace = "default"
+			Expect(client.Create(ctx, src)).To(Succeed())
+			defer func() {
+				Expect(client.Delete(ctx, src)).To(Succeed())
+			}()
+
+			ingress := &networkingv1.Ingress{}
+			ingress.Name = "test"
+			ingress.Namespace = ns.Name
+			ingress.Annotations = map[string]string{
+				v1alpha1.SecretReplicationFromNamespaceAnnotation: src.Namespace,
+			}
+			ingress.Spec.TLS = []networkingv1.IngressTLS{
+				{
+					Hosts:      []string{"example.com"},
+					SecretName: src.Name,
+				},
+			}
+			validator := webhooks.NewIngressValidator(logr.Discard(), client, decoder)
+			res := validator.Handle(ctx, admissionRequest(admissionv1.Create, ingress, nil))
+			Expect(res.Allowed).To(BeFalse())
+
+			src.Annotations = map[string]string{
+				v1alpha1.SecretReplicationAllowedNamespacesAnnotation: ns.Name,
+			}
+			Expect(client.Update(ctx, src)).To(Succeed())
+			res = validator.Handle(ctx, admissionRequest(admissionv1.Create, ingress, nil))
+			Expect(res.Allowed).To(BeTrue())
+		})
+	})
+})
+
+// admissionRequest creates an admission request for the given object.
+func admissionRequest(operation admissionv1.Operation, obj, oldObj ctrlclient.Object) admission.Request {
+	gvks, _, err := scheme.Scheme.ObjectKinds(obj)
+	Expect(err).ToNot(HaveOccurred())
+	raw, err := json.Marshal(obj)
+	Expect(err).ToNot(HaveOccurred())
+
+	req := admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: operation,
+			Kind:      metav1.GroupVersionKind{Group: gvks[0].Group, Version: gvks[0].Version, Kind: gvks[0].Kind},
+			Name:      obj.GetName(),
+			Namespace: obj.GetNamespace(),
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+	if oldObj != nil {
+		oldRaw, err := json.Marshal(oldObj)
+		Expect(err).ToNot(HaveOccurred())
+		req.OldObject = runtime.RawExtension{Raw: oldRaw}
+	}
+	return req
+}